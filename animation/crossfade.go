@@ -0,0 +1,165 @@
+package animation
+
+import (
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/demos"
+	"github.com/g3n/g3nd/util"
+)
+
+func init() {
+	demos.Map["animation.crossfade"] = &AnimationCrossFade{}
+}
+
+type AnimationCrossFade struct {
+	mixer    *util.Mixer
+	weights  []*gui.Slider // Weight slider of each clip
+	updating bool          // Weight sliders are being updated from the mixer
+}
+
+func (t *AnimationCrossFade) Initialize(a *app.App) {
+
+	// Adds white directional front light
+	dir1 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	dir1.SetPosition(0, 5, 10)
+	a.Scene().Add(dir1)
+
+	// Adds grid helper
+	grid := graphic.NewGridHelper(10, 0.5, &math32.Color{0.4, 0.4, 0.4})
+	a.Scene().Add(grid)
+
+	a.Camera().GetCamera().SetPosition(0, 1.5, 4)
+	a.Camera().GetCamera().LookAt(&math32.Vector3{0, 1, 0})
+
+	// Builds a simple character with pivot nodes for the arms and legs
+	mat := material.NewStandard(&math32.Color{0.3, 0.5, 0.8})
+	robot := core.NewNode()
+	a.Scene().Add(robot)
+	upper := core.NewNode()
+	robot.Add(upper)
+	torso := graphic.NewMesh(geometry.NewBox(0.6, 0.8, 0.3), mat)
+	torso.SetPosition(0, 1.2, 0)
+	upper.Add(torso)
+	head := graphic.NewMesh(geometry.NewSphere(0.18, 16, 16, 0, math32.Pi*2, 0, math32.Pi), mat)
+	head.SetPosition(0, 1.8, 0)
+	upper.Add(head)
+	limb := func(parent *core.Node, x, y, width, length float32) *core.Node {
+		pivot := core.NewNode()
+		pivot.SetPosition(x, y, 0)
+		mesh := graphic.NewMesh(geometry.NewBox(width, length, width), mat)
+		mesh.SetPosition(0, -length/2, 0)
+		pivot.Add(mesh)
+		parent.Add(pivot)
+		return pivot
+	}
+	armL := limb(upper, -0.4, 1.55, 0.15, 0.6)
+	armR := limb(upper, 0.4, 1.55, 0.15, 0.6)
+	legL := limb(robot, -0.15, 0.8, 0.2, 0.8)
+	legR := limb(robot, 0.15, 0.8, 0.2, 0.8)
+
+	// Creates the clips
+	axisX := &math32.Vector3{1, 0, 0}
+	axisZ := &math32.Vector3{0, 0, 1}
+	idle := newClip()
+	addPosition(idle, upper, []float32{0, 1, 2}, []float32{0, -0.03, 0})
+	addRotation(idle, armL, axisZ, []float32{0, 1, 2}, []float32{-5, -8, -5})
+	addRotation(idle, armR, axisZ, []float32{0, 1, 2}, []float32{5, 8, 5})
+	walk := newClip()
+	addRotation(walk, armL, axisX, []float32{0, 0.5, 1}, []float32{30, -30, 30})
+	addRotation(walk, armR, axisX, []float32{0, 0.5, 1}, []float32{-30, 30, -30})
+	addRotation(walk, legL, axisX, []float32{0, 0.5, 1}, []float32{-25, 25, -25})
+	addRotation(walk, legR, axisX, []float32{0, 0.5, 1}, []float32{25, -25, 25})
+	wave := newClip()
+	addRotation(wave, armR, axisZ, []float32{0, 0.5, 1}, []float32{150, 110, 150})
+
+	// Creates the mixer with the idle clip playing
+	t.mixer = util.NewMixer(robot)
+	names := []string{"Idle", "Walk", "Wave"}
+	clips := []*util.MixerClip{t.mixer.Add(idle, 1), t.mixer.Add(walk, 0), t.mixer.Add(wave, 0)}
+
+	// Adds controls
+	if a.ControlFolder() == nil {
+		return
+	}
+	g1 := a.ControlFolder().AddGroup("Crossfade")
+	s1 := g1.AddSlider("Fade time:", 2, 0.25)
+	for i, name := range names {
+		clip := clips[i]
+		b := gui.NewButton(name)
+		b.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			t.mixer.CrossFade(clip, s1.Value())
+		})
+		g1.AddPanel(b)
+	}
+	g2 := a.ControlFolder().AddGroup("Weights")
+	t.weights = nil
+	for i, name := range names {
+		clip := clips[i]
+		s := g2.AddSlider(name+":", 1, clip.Weight())
+		s.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			if !t.updating {
+				clip.SetWeight(s.Value())
+			}
+		})
+		t.weights = append(t.weights, s)
+	}
+}
+
+func (t *AnimationCrossFade) Render(a *app.App) {
+
+	t.mixer.Update(a.FrameDeltaSeconds())
+
+	// Shows the current weights
+	t.updating = true
+	clips := t.mixer.Clips()
+	for i, s := range t.weights {
+		s.SetValue(clips[i].Weight())
+	}
+	t.updating = false
+}
+
+// newClip creates and returns a looping animation
+func newClip() *animation.Animation {
+
+	anim := animation.NewAnimation()
+	anim.SetLoop(true)
+	return anim
+}
+
+// addPosition adds a channel which moves the node vertically by the specified offsets
+func addPosition(anim *animation.Animation, node *core.Node, times, offsets []float32) {
+
+	pos := node.Position()
+	keyframes := math32.NewArrayF32(0, len(times))
+	keyframes.Append(times...)
+	values := math32.NewArrayF32(0, len(offsets)*3)
+	for _, offs := range offsets {
+		values.Append(pos.X, pos.Y+offs, pos.Z)
+	}
+	ch := animation.NewPositionChannel(node)
+	ch.SetBuffers(keyframes, values)
+	anim.AddChannel(ch)
+}
+
+// addRotation adds a channel which rotates the node around the specified axis by the angles in degrees
+func addRotation(anim *animation.Animation, node *core.Node, axis *math32.Vector3, times, angles []float32) {
+
+	keyframes := math32.NewArrayF32(0, len(times))
+	keyframes.Append(times...)
+	values := math32.NewArrayF32(0, len(angles)*4)
+	var q math32.Quaternion
+	for _, angle := range angles {
+		q.SetFromAxisAngle(axis, math32.DegToRad(angle))
+		values.Append(q.X, q.Y, q.Z, q.W)
+	}
+	ch := animation.NewRotationChannel(node)
+	ch.SetBuffers(keyframes, values)
+	anim.AddChannel(ch)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/g3n/engine/audio/al"
 	"github.com/g3n/engine/camera/control"
+	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/gui"
 	"github.com/g3n/engine/light"
 	"github.com/g3n/engine/math32"
@@ -18,22 +19,45 @@ import (
 	"github.com/g3n/engine/util/logger"
 	"github.com/g3n/engine/util/stats"
 	"github.com/g3n/engine/window"
+	"github.com/g3n/g3nd/util"
 	"github.com/kardianos/osext"
 )
 
 // App contains the application state
 type App struct {
-	*application.Application                    // Embedded standard application object
-	log                      *logger.Logger     // Application logger
-	currentDemo              IDemo              // current test object
-	dirData                  string             // full path of data directory
-	labelFPS                 *gui.Label         // header FPS label
-	treeTests                *gui.Tree          // tree with test names
-	stats                    *stats.Stats       // statistics object
-	statsTable               *stats.StatsTable  // statistics table panel
-	control                  *gui.ControlFolder // Pointer to gui control panel
-	ambLight                 *light.Ambient     // Scene default ambient light
-	finalizers               []func()           // List of demo finalizers functions
+	*application.Application                            // Embedded standard application object
+	log                      *logger.Logger             // Application logger
+	currentDemo              IDemo                      // current test object
+	currentName              string                     // current test name
+	dirData                  string                     // full path of data directory
+	labelFPS                 *gui.Label                 // header FPS label
+	treeTests                *gui.Tree                  // tree with test names
+	stats                    *stats.Stats               // statistics object
+	statsTable               *stats.StatsTable          // statistics table panel
+	control                  *gui.ControlFolder         // Pointer to gui control panel
+	ambLight                 *light.Ambient             // Scene default ambient light
+	finalizers               []func()                   // List of demo finalizers functions
+	taskQueue                taskQueue                  // Functions queued to run in the main thread
+	cache                    *assetCache                // Asset cache shared by the demos
+	cacheLabel               *gui.Label                 // Asset cache statistics in the stats panel
+	demoMap                  map[string]IDemo           // Map of demo names to demo objects
+	config                   *Config                    // User configuration
+	theme                    *Theme                     // Current GUI theme
+	rebuildGui               bool                       // Rebuild GUI in the next frame
+	uiScale                  float32                    // GUI scale factor
+	camMode                  string                     // Current camera controller mode
+	camCtl                   cameraController           // Current camera controller or nil for orbit mode
+	camInput                 *cameraInput               // Keyboard and mouse state for the camera controllers
+	camStarted               bool                       // Camera controller was started from the current pose
+	camTrans                 *cameraTransition          // Current camera bookmark transition
+	camPath                  *cameraPathState           // Camera path of the current demo
+	viewMode                 int                        // Number of viewports
+	viewports                []*viewport                // Viewports when the demo panel is split
+	viewPlaced               bool                       // Viewport cameras were placed around the scene
+	viewEmpty                *core.Node                 // Empty scene rendered with the GUI over the viewports
+	viewWireframe            []wireframeState           // Wireframe state of the materials changed for the main viewport
+	viewDragging             bool                       // Mouse button pressed over a viewport
+	groundHeight             func(x, z float32) float32 // Ground height function for the walk mode
 }
 
 // IDemo is the interface that must be satisfied for all demo objects
@@ -51,6 +75,9 @@ var (
 	oLogs        = flag.String("logs", "", "Set log levels for packages. Ex: gui:debug,gls:info")
 	oStats       = flag.Bool("stats", false, "Shows statistics control panel in the GUI")
 	oRenderStats = flag.Bool("renderstats", false, "Shows gui renderer statistics in the console")
+	oSaveScene   = flag.String("savescene", "", "Saves the scene of the specified demo to a YAML, JSON or glTF file and exits")
+	oStyle       = flag.String("style", "", "Loads a custom GUI style file and saves it as the current theme")
+	oTexPath     = flag.String("texpath", "", "Additional directories to search for model textures, separated by the OS path list separator")
 )
 
 const (
@@ -61,11 +88,17 @@ const (
 )
 
 // Create creates the G3ND application using the specified map of demos
-func Create(demoMap map[string]IDemo) *App {
+// and the optional information about the demos
+func Create(demoMap map[string]IDemo, demoInfo map[string]*DemoInfo) *App {
 
 	// Sets the application usage
 	flag.Usage = usage
 
+	// Runs subcommands and options which do not need the application window
+	if runCommand(demoMap) || runInfo(demoMap, demoInfo) || runValidate() {
+		return nil
+	}
+
 	// Creates standard application object
 	a, err := application.Create(application.Options{
 		Title:       progName,
@@ -84,6 +117,12 @@ func Create(demoMap map[string]IDemo) *App {
 	app.Application = a
 	app.log = app.Log()
 	app.log.Info("%s v%d.%d starting", progName, vmajor, vminor)
+
+	// Saving a scene requires the demo which builds it
+	if *oSaveScene != "" && len(flag.Args()) == 0 {
+		fmt.Fprintf(os.Stderr, "%s: -savescene requires a demo name\n", execName)
+		os.Exit(2)
+	}
 	app.stats = stats.NewStats(app.Gl())
 
 	// Apply log levels to engine package loggers
@@ -115,12 +154,49 @@ func Create(demoMap map[string]IDemo) *App {
 	app.dirData = app.checkDirData("data")
 	app.log.Info("Using data directory:%s", app.dirData)
 
+	// Textures not found near the loaded models are searched in the data images
+	// directory and then in the directories from the command line
+	util.SetTexturePaths(append([]string{filepath.Join(app.dirData, "images")}, filepath.SplitList(*oTexPath)...)...)
+
 	// Open default audio device
 	err = app.OpenDefaultAudioDevice()
 	if err != nil {
 		app.log.Error("%v", err)
 	}
 
+	// Loads user configuration
+	app.demoMap = demoMap
+	app.config, err = loadConfig()
+	if err != nil {
+		app.log.Error("Error loading configuration:%v", err)
+	}
+
+	// Sets the GUI scale factor
+	app.initUIScale()
+	app.camMode = camOrbit
+	app.viewMode = viewSingle
+	app.taskQueue.budget = defaultTaskBudget
+	app.resetTasks()
+	app.initCache()
+
+	// Sets the GUI theme from the command line or the user configuration
+	if *oStyle != "" {
+		app.config.StyleFile = *oStyle
+		app.config.Theme = themeCustom
+		app.saveConfig()
+	}
+	themeName := app.config.Theme
+	if themeName == "" {
+		themeName = themeLight
+	}
+	theme, err := app.themeByName(themeName)
+	if err != nil {
+		app.log.Error("Error setting theme:%v", err)
+		theme, _ = newTheme(themeLight)
+	}
+	app.setTheme(theme)
+	app.rebuildGui = false
+
 	// Builds user interface
 	if *oNogui == false {
 		app.buildGui(demoMap)
@@ -136,6 +212,7 @@ func Create(demoMap map[string]IDemo) *App {
 		for name, test := range demoMap {
 			if name == tname {
 				app.currentDemo = test
+				app.currentName = name
 				app.currentDemo.Initialize(app)
 				break
 			}
@@ -145,10 +222,23 @@ func Create(demoMap map[string]IDemo) *App {
 			usage()
 			return nil
 		}
+		app.startFlythrough()
 	}
 
 	// Subscribe to before render events to call current test Render method
 	app.Subscribe(application.OnBeforeRender, func(evname string, ev interface{}) {
+		// Rebuilds the GUI and restarts the current demo after a theme change
+		if app.rebuildGui {
+			app.rebuildGui = false
+			if *oNogui == false {
+				app.Gui().DisposeChildren(true)
+				app.buildGui(app.demoMap)
+			}
+			app.restartDemo()
+		}
+		app.runTasks()
+		app.updateCamera()
+		app.updateViewports()
 		if app.currentDemo != nil {
 			app.currentDemo.Render(app)
 		}
@@ -156,10 +246,13 @@ func Create(demoMap map[string]IDemo) *App {
 
 	// Subscribe to after render events to update the FPS
 	app.Subscribe(application.OnAfterRender, func(evname string, ev interface{}) {
+		// Renders the additional viewports
+		app.renderViewports()
 		// Update statistics
 		if app.stats.Update(time.Second) {
 			if app.statsTable != nil {
 				app.statsTable.Update(app.stats)
+				app.cacheLabel.SetText(app.cacheStatsText())
 			}
 		}
 		// Update render stats
@@ -171,6 +264,14 @@ func Create(demoMap map[string]IDemo) *App {
 		}
 		// Update FPS
 		app.updateFPS()
+		// Saves the scene of the demo specified in the command line after its first frame
+		if *oSaveScene != "" && app.currentDemo != nil {
+			err := app.SaveScene(*oSaveScene)
+			if err != nil {
+				app.log.Error("%v", err)
+			}
+			app.Quit()
+		}
 	})
 	return app
 }
@@ -208,6 +309,56 @@ func (app *App) AddFinalizer(f func()) {
 	app.finalizers = append(app.finalizers, f)
 }
 
+// restartDemo resets the scene and initializes the current demo again
+func (app *App) restartDemo() {
+
+	app.setupScene()
+	if app.currentDemo != nil {
+		app.currentDemo.Initialize(app)
+	}
+}
+
+// SaveScene saves the current scene and camera pose to the specified scene file.
+// The format is JSON if the file extension is ".json", a model format if there is
+// a model writer registered for the extension, as for glTF, and YAML otherwise.
+// Model files keep the meshes of the scene but not its camera.
+func (app *App) SaveScene(fpath string) error {
+
+	if write := util.FindModelWriter(fpath); write != nil {
+		warnings, err := write(app.Scene(), fpath)
+		for _, w := range warnings {
+			app.log.Warn("%s", w)
+		}
+		if err != nil {
+			return err
+		}
+		app.log.Info("Scene saved to:%s", fpath)
+		return nil
+	}
+	sf := util.NewSceneFileFromNode(app.Scene(), app.Camera(), app.ambLight)
+	err := sf.SaveSceneFile(fpath)
+	if err != nil {
+		return err
+	}
+	app.log.Info("Scene saved to:%s", fpath)
+	return nil
+}
+
+// saveSceneDefault saves the current scene to a file in the current directory
+// named after the current demo and the current time.
+func (app *App) saveSceneDefault(ext string) {
+
+	name := app.currentName
+	if name == "" {
+		name = "scene"
+	}
+	fname := fmt.Sprintf("%s-%s%s", name, time.Now().Format("20060102-150405"), ext)
+	err := app.SaveScene(fname)
+	if err != nil {
+		app.log.Error("Error saving scene:%v", err)
+	}
+}
+
 // UpdateFPS updates the fps value in the window title or header label
 func (app *App) updateFPS() {
 
@@ -239,6 +390,9 @@ func (app *App) setupScene() {
 	}
 	app.finalizers = app.finalizers[0:0]
 
+	// Cancel the background work of the previous demo and discard its queued tasks
+	app.resetTasks()
+
 	// Cancel next events and clear all window subscriptions
 	app.Window().CancelDispatch()
 	app.Window().ClearSubscriptions()
@@ -314,6 +468,30 @@ func (app *App) setupScene() {
 	// to avoid GUI events being propagated to the orbit control.
 	app.SetOrbit(control.NewOrbitControl(app.Camera(), app.Window()))
 
+	// Recreates the camera controller of the current mode
+	app.groundHeight = nil
+	app.camInput = newCameraInput(app.Window(), func() bool { return app.camCtl != nil })
+	app.setCameraMode(app.camMode)
+
+	// Subscribes to the camera bookmark keys
+	app.camTrans = nil
+	app.Window().Subscribe(window.OnKeyDown, app.onBookmarkKey)
+
+	// Stops the recording or playback of the camera path
+	if app.camPath != nil {
+		app.camPath.recording = false
+		app.camPath.playing = false
+	}
+
+	// Recreates the viewports, whose panels were disposed with the demo panel children
+	app.viewports = nil
+	app.Window().Subscribe(window.OnCursor, app.onViewportCursor)
+	app.Window().Subscribe(window.OnMouseDown, app.onViewportCursor)
+	app.Window().Subscribe(window.OnMouseUp, app.onViewportCursor)
+	if app.Panel3D() != nil && app.viewMode != viewSingle {
+		app.buildViewports()
+	}
+
 	// If audio active, resets global listener parameters
 	al.Listener3f(al.Position, 0, 0, 0)
 	al.Listener3f(al.Velocity, 0, 0, 0)
@@ -340,8 +518,14 @@ func (app *App) setupScene() {
 		// Recreates orbit camera control
 		app.Orbit().Dispose()
 		app.SetOrbit(control.NewOrbitControl(app.Camera(), app.Window()))
+		// Restarts the camera controller from the pose of the new camera
+		app.Orbit().Enabled = app.camCtl == nil
+		app.camStarted = false
 	})
 
+	// Adds camera controller selection
+	app.addCameraModeControl()
+
 	// Adds ambient light slider
 	s1 := app.control.AddSlider("Ambient light:", 2.0, app.ambLight.Intensity())
 	s1.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
@@ -366,11 +550,12 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 
 	// Adds header after the gui central panel to ensure that the control folder
 	// stays over the gui panel when opened.
-	headerColor := math32.Color4{13.0/256.0, 41.0/256.0, 62.0/256.0, 1}
-	lightTextColor := math32.Color4{0.8, 0.8, 0.8, 1}
-	header := gui.NewPanel(600, 40)
+	headerColor := app.theme.Header
+	lightTextColor := app.theme.HeaderText
+	header := gui.NewPanel(app.Scaled(600), app.Scaled(40))
 	header.SetBorders(0, 0, 1, 0)
-	header.SetPaddings(4, 4, 4, 4)
+	pad := app.Scaled(4)
+	header.SetPaddings(pad, pad, pad, pad)
 	header.SetColor4(&headerColor)
 	header.SetLayoutParams(&gui.DockLayoutParams{Edge: gui.DockTop})
 
@@ -382,11 +567,12 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 	// Add an optional image to header
 	logo, err := gui.NewImage(app.dirData + "/images/g3n_logo_32.png")
 	if err == nil {
-		logo.SetContentAspectWidth(32)
+		logo.SetContentAspectWidth(app.Scaled(32))
 		header.Add(logo)
 	}
 
 	// Header title
+	// The fonts are scaled by the GUI scale factor
 	const fontSize = 20
 	title := gui.NewLabel(" ")
 	title.SetFontSize(fontSize)
@@ -410,6 +596,84 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 		header.Add(app.labelFPS)
 	}
 
+	// Scene menu
+	mb := gui.NewMenuBar()
+	mb.SetLayoutParams(&gui.HBoxLayoutParams{AlignV: gui.AlignCenter})
+	mScene := gui.NewMenu()
+	mScene.AddOption("Save as YAML").SetId("yaml")
+	mScene.AddOption("Save as JSON").SetId("json")
+	mScene.AddOption("Save as glTF").SetId("glb")
+	mb.AddMenu("Scene", mScene)
+	mPath := gui.NewMenu()
+	mPath.AddOption("Record").SetId("record")
+	mPath.AddOption("Add key").SetId("addkey")
+	mPath.AddOption("Play").SetId("play")
+	mPath.AddOption("Play loop").SetId("loop")
+	mPath.AddOption("Stop").SetId("stop")
+	mPath.AddSeparator()
+	mPath.AddOption("Save").SetId("savepath")
+	mPath.AddOption("Load").SetId("loadpath")
+	mb.AddMenu("Camera path", mPath)
+	mView := gui.NewMenu()
+	mView.AddOption("Single view").SetId("view1")
+	mView.AddOption("Two views").SetId("view2")
+	mView.AddOption("Quad view").SetId("view4")
+	mb.AddMenu("View", mView)
+	mb.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		switch ev.(*gui.MenuItem).Id() {
+		case "yaml":
+			app.saveSceneDefault(".yaml")
+		case "json":
+			app.saveSceneDefault(".json")
+		case "glb":
+			app.saveSceneDefault(".glb")
+		case "record":
+			app.startRecording()
+		case "addkey":
+			app.addCameraKey()
+		case "play":
+			app.playCameraPath(false)
+		case "loop":
+			app.playCameraPath(true)
+		case "stop":
+			app.stopCameraPath()
+		case "savepath":
+			app.saveCameraPath()
+		case "loadpath":
+			app.camPath = nil
+			app.cameraPath()
+		case "view1":
+			app.setViewMode(viewSingle)
+		case "view2":
+			app.setViewMode(viewDual)
+		case "view4":
+			app.setViewMode(viewQuad)
+		}
+	})
+	header.Add(mb)
+
+	// Theme selection
+	ddTheme := gui.NewDropDown(app.Scaled(120), gui.NewImageLabel(""))
+	ddTheme.SetLayoutParams(&gui.HBoxLayoutParams{AlignV: gui.AlignCenter})
+	names := themeNames
+	if app.config.StyleFile != "" {
+		names = append(names[:len(names):len(names)], themeCustom)
+	}
+	for _, name := range names {
+		item := gui.NewImageLabel(name)
+		ddTheme.Add(item)
+		if name == app.theme.Name {
+			ddTheme.SetSelected(item)
+		}
+	}
+	ddTheme.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		sel := ddTheme.Selected()
+		if sel != nil && sel.Text() != app.theme.Name {
+			app.selectTheme(sel.Text())
+		}
+	})
+	header.Add(ddTheme)
+
 	// New styles for control folder
 	styles := gui.StyleDefault().ControlFolder
 	styles.Folder.Normal.BgColor = headerColor
@@ -425,14 +689,18 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 		header.Add(spacer)
 
 		// Creates control folder for statistics table
-		statsControlFolder := gui.NewControlFolder("Stats", 100)
+		statsControlFolder := gui.NewControlFolder("Stats", app.Scaled(100))
 		statsControlFolder.SetLayoutParams(&gui.HBoxLayoutParams{AlignV: gui.AlignBottom})
 		statsControlFolder.SetStyles(&styles)
 		header.Add(statsControlFolder)
 
 		// Adds stats table in the control folder
-		app.statsTable = stats.NewStatsTable(220, 200, app.Gl())
+		app.statsTable = stats.NewStatsTable(app.Scaled(220), app.Scaled(200), app.Gl())
 		statsControlFolder.AddPanel(app.statsTable)
+
+		// Adds asset cache statistics below the stats table
+		app.cacheLabel = gui.NewLabel(app.cacheStatsText())
+		statsControlFolder.AddPanel(app.cacheLabel)
 	}
 
 	// Adds spacer to right justify the control folder in the header
@@ -441,13 +709,13 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 	header.Add(spacer)
 
 	// Adds control folder in the header
-	app.control = gui.NewControlFolder("Controls", 100)
+	app.control = gui.NewControlFolder("Controls", app.Scaled(100))
 	app.control.SetLayoutParams(&gui.HBoxLayoutParams{AlignV: gui.AlignBottom})
 	app.control.SetStyles(&styles)
 	header.Add(app.control)
 
 	// Test list
-	app.treeTests = gui.NewTree(175, 0)
+	app.treeTests = gui.NewTree(app.Scaled(175), 0)
 	app.treeTests.SetLayoutParams(&gui.DockLayoutParams{Edge: gui.DockLeft})
 	// Sort test names
 	tnames := []string{}
@@ -487,11 +755,23 @@ func (app *App) buildGui(demoMap map[string]IDemo) {
 			test := label.GetNode().UserData().(IDemo)
 			test.Initialize(app)
 			app.currentDemo = test
+			app.currentName = demoName(demoMap, test)
 		}
 	})
 	app.Gui().Add(app.treeTests)
 }
 
+// demoName returns the name of the specified demo in the demo map
+func demoName(demoMap map[string]IDemo, demo IDemo) string {
+
+	for name, d := range demoMap {
+		if d == demo {
+			return name
+		}
+	}
+	return ""
+}
+
 // logStats generate log with current statistics
 func (app *App) logStats() {
 
@@ -519,26 +799,41 @@ Draw calls/frame: %d
 // Aborts if not found
 func (app *App) checkDirData(dirDataName string) string {
 
+	dirData, err := findDirData(dirDataName)
+	if err != nil {
+		panic(err)
+	}
+	if dirData == "" {
+		// Shows error message and aborts
+		app.log.Fatal("Data directory NOT FOUND")
+	}
+	return dirData
+}
+
+// findDirData try to find and return the complete data directory path.
+// Returns an empty string if not found.
+func findDirData(dirDataName string) (string, error) {
+
 	// Checks first if data directory is in the current directory
 	if _, err := os.Stat(dirDataName); err == nil {
 		dirData, err := filepath.Abs(dirDataName)
 		if err != nil {
-			panic(err)
+			return "", err
 		}
-		return dirData
+		return dirData, nil
 	}
 
 	// Get the executable path
 	execPath, err := osext.Executable()
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	// Checks if data directory is in the executable directory
 	execDir := filepath.Dir(execPath)
 	path := filepath.Join(execDir, dirDataName)
 	if _, err := os.Stat(path); err == nil {
-		return path
+		return path, nil
 	}
 
 	// Assumes the executable is in $GOPATH/bin
@@ -546,7 +841,7 @@ func (app *App) checkDirData(dirDataName string) string {
 	path = filepath.Join(goPath, "src", "github.com", "g3n", "g3nd", dirDataName)
 	// Checks data path
 	if _, err := os.Stat(path); err == nil {
-		return path
+		return path, nil
 	}
 
 	// If the data directory hasn't been found, manually scan the $GOPATH directories
@@ -556,13 +851,10 @@ func (app *App) checkDirData(dirDataName string) string {
 		// Checks data path
 		path = filepath.Join(j, "src", "github.com", "g3n", "g3nd", dirDataName)
 		if _, err := os.Stat(path); err == nil {
-			return path
+			return path, nil
 		}
 	}
-
-	// Shows error message and aborts
-	app.log.Fatal("Data directory NOT FOUND")
-	return ""
+	return "", nil
 }
 
 // usage shows the application usage
@@ -570,6 +862,14 @@ func usage() {
 
 	fmt.Fprintf(os.Stderr, "%s v%d.%d\n", progName, vmajor, vminor)
 	fmt.Fprintf(os.Stderr, "usage: %s [options] [<test>] \n", execName)
+	cmdNames := []string{}
+	for name := range commands {
+		cmdNames = append(cmdNames, name)
+	}
+	sort.Strings(cmdNames)
+	for _, name := range cmdNames {
+		fmt.Fprintf(os.Stderr, "       %s %s\n", execName, commands[name].usage)
+	}
 	flag.PrintDefaults()
 	os.Exit(2)
 }
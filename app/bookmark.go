@@ -0,0 +1,123 @@
+package app
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// CameraBookmark is a camera pose saved by the user for a demo
+type CameraBookmark struct {
+	Position math32.Vector3 `json:"position"`       // Camera position
+	Target   math32.Vector3 `json:"target"`         // Camera target
+	Fov      float32        `json:"fov,omitempty"`  // Field of view of the perspective camera
+	Zoom     float32        `json:"zoom,omitempty"` // Zoom of the orthographic camera
+}
+
+// Bookmark slots and duration of the transition to a recalled bookmark in seconds
+const (
+	numBookmarks       = 9
+	bookmarkTransition = 0.75
+)
+
+// cameraTransition is the state of the animated transition between two camera poses
+type cameraTransition struct {
+	from    CameraBookmark // Initial pose
+	to      CameraBookmark // Final pose
+	elapsed float32        // Elapsed time in seconds
+}
+
+// onBookmarkKey stores the current camera pose when Ctrl+1..9 is pressed
+// and recalls the pose stored in the slot when 1..9 is pressed.
+func (app *App) onBookmarkKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	if kev.Keycode < window.Key1 || kev.Keycode > window.Key9 || app.currentName == "" {
+		return
+	}
+	slot := int(kev.Keycode - window.Key1)
+	switch kev.Mods {
+	case window.ModControl:
+		app.storeBookmark(slot)
+	case 0:
+		app.recallBookmark(slot)
+	}
+}
+
+// storeBookmark stores the current camera pose in the specified slot
+// of the current demo and saves the user configuration.
+func (app *App) storeBookmark(slot int) {
+
+	if app.config.Bookmarks == nil {
+		app.config.Bookmarks = make(map[string][]*CameraBookmark)
+	}
+	marks := app.config.Bookmarks[app.currentName]
+	if len(marks) < numBookmarks {
+		marks = append(marks, make([]*CameraBookmark, numBookmarks-len(marks))...)
+	}
+	b := app.cameraPose()
+	marks[slot] = &b
+	app.config.Bookmarks[app.currentName] = marks
+	app.saveConfig()
+	app.log.Info("Stored camera bookmark %d for demo:%s", slot+1, app.currentName)
+}
+
+// recallBookmark starts the transition to the camera pose stored in the
+// specified slot of the current demo. Does nothing if the slot is empty,
+// so demos which use the number keys are not affected.
+func (app *App) recallBookmark(slot int) {
+
+	marks := app.config.Bookmarks[app.currentName]
+	if slot >= len(marks) || marks[slot] == nil {
+		return
+	}
+	app.camTrans = &cameraTransition{from: app.cameraPose(), to: *marks[slot]}
+}
+
+// cameraPose returns the pose of the current camera
+func (app *App) cameraPose() CameraBookmark {
+
+	cam := app.Camera().GetCamera()
+	b := CameraBookmark{Position: cam.Position(), Target: cam.Target()}
+	switch c := app.Camera().(type) {
+	case *camera.Perspective:
+		b.Fov = c.Fov()
+	case *camera.Orthographic:
+		b.Zoom = c.Zoom()
+	}
+	return b
+}
+
+// updateCameraTransition updates the camera pose during a bookmark transition.
+// When the transition ends, the camera controller is restarted from the new pose.
+func (app *App) updateCameraTransition() {
+
+	tr := app.camTrans
+	tr.elapsed += app.FrameDeltaSeconds()
+	t := math32.Min(tr.elapsed/bookmarkTransition, 1)
+	// Smoothstep easing
+	t = t * t * (3 - 2*t)
+
+	pos := tr.from.Position
+	pos.Lerp(&tr.to.Position, t)
+	target := tr.from.Target
+	target.Lerp(&tr.to.Target, t)
+	cam := app.Camera().GetCamera()
+	cam.SetPositionVec(&pos)
+	cam.LookAt(&target)
+	switch c := app.Camera().(type) {
+	case *camera.Perspective:
+		if tr.to.Fov > 0 {
+			c.SetFov(tr.from.Fov + (tr.to.Fov-tr.from.Fov)*t)
+		}
+	case *camera.Orthographic:
+		if tr.to.Zoom > 0 {
+			c.SetZoom(tr.from.Zoom + (tr.to.Zoom-tr.from.Zoom)*t)
+		}
+	}
+
+	if tr.elapsed >= bookmarkTransition {
+		app.camTrans = nil
+		app.camStarted = false
+	}
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"container/list"
+	"flag"
+	"fmt"
+	"image"
+
+	"github.com/g3n/engine/audio"
+	"github.com/g3n/engine/loader/obj"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Command line option for the asset cache memory budget
+var oCacheMB = flag.Int("cachemb", 256, "Memory budget of the asset cache in megabytes")
+
+// assetCache keeps decoded assets shared by the demos, such as textures, keyed by
+// their path and import options. The cache holds its own reference to each asset,
+// so assets disposed with the scene of a demo stay available for the next demos.
+// When the cache size exceeds its budget the least recently used assets are released.
+// Assets still in use by the current scene are only freed when the scene releases them.
+// The cache must only be used in the main thread, as releasing assets may call OpenGL.
+type assetCache struct {
+	entries   map[string]*assetEntry
+	lru       *list.List // Entries ordered from the most to the least recently used
+	budget    int        // Memory budget in bytes
+	size      int        // Total size of the cached assets in bytes
+	hits      int        // Number of requests found in the cache
+	misses    int        // Number of requests loaded from disk
+	evictions int        // Number of assets released to fit the budget
+}
+
+// assetEntry is a cached asset
+type assetEntry struct {
+	key     string
+	value   interface{}
+	size    int           // Estimated memory size in bytes
+	release func()        // Releases the reference held by the cache
+	elem    *list.Element // Element in the LRU list
+}
+
+// CacheStats contains the statistics of the asset cache
+type CacheStats struct {
+	Entries   int // Number of cached assets
+	Size      int // Total size of the cached assets in bytes
+	Budget    int // Memory budget in bytes
+	Hits      int // Number of requests found in the cache
+	Misses    int // Number of requests loaded from disk
+	Evictions int // Number of assets released to fit the budget
+}
+
+// TextureOptions are the import options of a cached texture.
+// The zero value uses the engine defaults.
+type TextureOptions struct {
+	NoFlipY bool           // Do not flip the image vertically
+	WrapS   uint32         // Wrap mode for the S coordinate or zero for the default
+	WrapT   uint32         // Wrap mode for the T coordinate or zero for the default
+	Repeat  math32.Vector2 // Repeat factors or zero for the default
+}
+
+// initCache creates the asset cache with the budget from the command line
+func (app *App) initCache() {
+
+	app.cache = &assetCache{
+		entries: make(map[string]*assetEntry),
+		lru:     list.New(),
+		budget:  *oCacheMB * 1024 * 1024,
+	}
+}
+
+// SetCacheBudget sets the memory budget of the asset cache in bytes,
+// releasing the least recently used assets if necessary
+func (app *App) SetCacheBudget(budget int) {
+
+	c := app.cache
+	c.budget = budget
+	c.evict(nil)
+}
+
+// CacheStats returns the current statistics of the asset cache
+func (app *App) CacheStats() CacheStats {
+
+	c := app.cache
+	return CacheStats{
+		Entries:   len(c.entries),
+		Size:      c.size,
+		Budget:    c.budget,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// CacheGet returns the asset with the specified key from the cache. If not found,
+// the asset is loaded with the specified function, which returns the asset,
+// its estimated size in bytes and the function which releases it.
+// The returned asset is shared: callers which dispose it must take
+// their own reference, as Texture does for textures. Must be called in the main thread.
+func (app *App) CacheGet(key string, load func() (interface{}, int, func(), error)) (interface{}, error) {
+
+	c := app.cache
+	if e, ok := c.entries[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(e.elem)
+		return e.value, nil
+	}
+	c.misses++
+	value, size, release, err := load()
+	if err != nil {
+		return nil, err
+	}
+	e := &assetEntry{key: key, value: value, size: size, release: release}
+	e.elem = c.lru.PushFront(e)
+	c.entries[key] = e
+	c.size += size
+	c.evict(e)
+	return value, nil
+}
+
+// Texture returns a texture for the specified image file and import options from the cache,
+// loading and caching it if not found. Each call takes a new reference to the texture,
+// which is released when the material using it is disposed. The texture is shared with
+// other demos and materials, so it must not be modified: its wrapping, repeat and flip
+// settings are set with the import options. Must be called in the main thread.
+func (app *App) Texture(path string, opts *TextureOptions) (*texture.Texture2D, error) {
+
+	return app.cachedTexture(path, opts, nil)
+}
+
+// cachedTexture returns a new reference to the texture for the specified image file and
+// import options from the cache. If not found, the texture is created from the specified
+// decoded image or, if nil, from the image file, and added to the cache.
+func (app *App) cachedTexture(path string, opts *TextureOptions, img *image.RGBA) (*texture.Texture2D, error) {
+
+	if opts == nil {
+		opts = &TextureOptions{}
+	}
+	value, err := app.CacheGet(textureKey(path, opts), func() (interface{}, int, func(), error) {
+		if img == nil {
+			var err error
+			img, err = texture.DecodeImage(path)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+		}
+		tex := texture.NewTexture2DFromRGBA(img)
+		if opts.NoFlipY {
+			tex.SetFlipY(false)
+		}
+		if opts.WrapS != 0 {
+			tex.SetWrapS(opts.WrapS)
+		}
+		if opts.WrapT != 0 {
+			tex.SetWrapT(opts.WrapT)
+		}
+		if opts.Repeat.X != 0 || opts.Repeat.Y != 0 {
+			tex.SetRepeat(opts.Repeat.X, opts.Repeat.Y)
+		}
+		// Mipmaps use one third more memory
+		return tex, len(img.Pix) * 4 / 3, tex.Dispose, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*texture.Texture2D).Incref(), nil
+}
+
+// OBJModel returns the decoded OBJ file with its MTL file from the cache, decoding and caching
+// them if not found. An empty MTL path uses the MTL file named in the OBJ file.
+// The decoder is shared with other demos, so it must not be modified. Its methods which
+// create geometries, meshes and groups create new engine objects for each call.
+// Must be called in the main thread.
+func (app *App) OBJModel(path, mtlpath string) (*obj.Decoder, error) {
+
+	value, err := app.CacheGet(fmt.Sprintf("obj:%s:%s", path, mtlpath), func() (interface{}, int, func(), error) {
+		dec, err := obj.Decode(path, mtlpath)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return dec, (len(dec.Vertices) + len(dec.Normals) + len(dec.Uvs)) * 4, nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*obj.Decoder), nil
+}
+
+// Estimated memory in bytes of the streaming buffers of an audio player
+const audioPlayerSize = 256 * 1024
+
+// cachedPlayer is an audio player kept by the cache with its default properties
+type cachedPlayer struct {
+	player  *audio.Player
+	inUse   bool // Used by the current demo
+	evicted bool // Released by the cache while in use and disposed when the demo ends
+	gain    float32
+	pitch   float32
+	inner   float32
+	outer   float32
+	looping bool
+}
+
+// AudioPlayer returns an audio player for the specified sound file from the cache, rewound
+// and with its default properties, or creates and caches a new one if none is available.
+// Players keep their playback state, so a cached player is used by a single caller of the
+// current demo and returned to the cache, removed from its parent node, before the next demo
+// is started. The player must not be disposed by the demo. Must be called in the main thread.
+func (app *App) AudioPlayer(path string) (*audio.Player, error) {
+
+	// Finds the first player of the file which is not used by the current demo
+	c := app.cache
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("audio:%s#%d", path, i)
+		if e, ok := c.entries[key]; !ok || !e.value.(*cachedPlayer).inUse {
+			break
+		}
+	}
+	value, err := app.CacheGet(key, func() (interface{}, int, func(), error) {
+		player, err := audio.NewPlayer(path)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		cp := &cachedPlayer{player: player, gain: player.Gain(), pitch: player.Pitch(),
+			inner: player.InnerCone(), outer: player.OuterCone(), looping: player.Looping()}
+		release := func() {
+			cp.evicted = true
+			if !cp.inUse {
+				cp.player.Dispose()
+			}
+		}
+		return cp, audioPlayerSize, release, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cp := value.(*cachedPlayer)
+	cp.reset()
+	cp.inUse = true
+	app.AddFinalizer(func() {
+		cp.inUse = false
+		cp.player.Stop()
+		if parent := cp.player.Parent(); parent != nil {
+			parent.GetNode().Remove(cp.player)
+		}
+		if cp.evicted {
+			cp.player.Dispose()
+		}
+	})
+	return cp.player, nil
+}
+
+// reset rewinds the player and restores the properties it had when created
+func (cp *cachedPlayer) reset() {
+
+	p := cp.player
+	p.Stop()
+	p.SetGain(cp.gain)
+	p.SetPitch(cp.pitch)
+	p.SetInnerCone(cp.inner)
+	p.SetOuterCone(cp.outer)
+	p.SetLooping(cp.looping)
+	p.SetRolloffFactor(1)
+	p.SetVelocity(0, 0, 0)
+	p.SetPosition(0, 0, 0)
+	p.SetRotation(0, 0, 0)
+}
+
+// textureKey returns the cache key of the texture for the specified image file and import options
+func textureKey(path string, opts *TextureOptions) string {
+
+	return fmt.Sprintf("texture:%s:%+v", path, *opts)
+}
+
+// cached returns if the asset with the specified key is in the cache
+func (c *assetCache) cached(key string) bool {
+
+	_, ok := c.entries[key]
+	return ok
+}
+
+// evict releases the least recently used assets, except the specified entry,
+// until the cache size fits its budget
+func (c *assetCache) evict(keep *assetEntry) {
+
+	for c.size > c.budget {
+		elem := c.lru.Back()
+		if elem == nil {
+			return
+		}
+		e := elem.Value.(*assetEntry)
+		if e == keep {
+			return
+		}
+		c.lru.Remove(elem)
+		delete(c.entries, e.key)
+		c.size -= e.size
+		c.evictions++
+		if e.release != nil {
+			e.release()
+		}
+	}
+}
+
+// cacheStatsText returns the asset cache statistics formatted for the stats panel
+func (app *App) cacheStatsText() string {
+
+	cs := app.CacheStats()
+	return fmt.Sprintf("Asset cache: %d assets %.1f/%.0f MB\nhits %d misses %d evictions %d",
+		cs.Entries, float64(cs.Size)/(1024*1024), float64(cs.Budget)/(1024*1024),
+		cs.Hits, cs.Misses, cs.Evictions)
+}
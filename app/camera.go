@@ -0,0 +1,373 @@
+package app
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/camera/control"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// Camera controller modes
+const (
+	camOrbit     = "orbit"
+	camFly       = "fly"
+	camWalk      = "walk"
+	camTurntable = "turntable"
+)
+
+// camModes contains the camera controller modes in the order shown in the control folder
+var camModes = []string{camOrbit, camFly, camWalk, camTurntable}
+
+// Parameters of the camera controllers
+const (
+	camLookSpeed      = 0.005              // Rotation in radians per pixel of mouse movement
+	camMaxPitch       = math32.Pi/2 - 0.01 // Maximum pitch/elevation angle in radians
+	camFastFactor     = 4                  // Speed multiplier when shift is pressed
+	camMinEyeHeight   = 0.1                // Minimum eye height above the ground in walk mode
+	camTurntableSpin  = 2 * math32.Pi / 20 // Turntable rotation speed in radians per second
+	camTurntableDelay = 2                  // Seconds without user input before the turntable rotates
+)
+
+// cameraController is the interface for the camera controllers other than
+// the engine orbit control. The controller is started in the frame after it is
+// selected or after the demo is initialized, so it uses the current camera pose.
+// The camera target is always updated so the pose is kept when switching controllers.
+type cameraController interface {
+	start(cam *camera.Camera)                               // Starts controlling the camera from its current pose
+	update(cam *camera.Camera, in *cameraInput, dt float32) // Updates the camera pose at each frame
+}
+
+// cameraInput keeps the state of the keyboard and mouse used by the camera controllers
+type cameraInput struct {
+	keys     map[window.Key]bool // Pressed keys
+	dragging bool                // Left mouse button is pressed
+	cx, cy   float32             // Last cursor position
+	dx, dy   float32             // Cursor movement while dragging since the last frame
+	scroll   float32             // Scroll offset since the last frame
+}
+
+// newCameraInput creates and returns the camera input state subscribed to the events
+// of the specified window. Events are ignored if the enabled function returns false.
+// It must be called after the GUI root panel subscribes to the window events,
+// so events consumed by the GUI are not received.
+func newCameraInput(win window.IWindow, enabled func() bool) *cameraInput {
+
+	in := new(cameraInput)
+	in.keys = make(map[window.Key]bool)
+	win.Subscribe(window.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		// Ignores keys used by the application shortcuts
+		if !enabled() || kev.Mods&(window.ModControl|window.ModAlt) != 0 {
+			return
+		}
+		in.keys[kev.Keycode] = true
+	})
+	win.Subscribe(window.OnKeyUp, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		delete(in.keys, kev.Keycode)
+	})
+	win.Subscribe(window.OnMouseDown, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		if !enabled() || mev.Button != window.MouseButtonLeft {
+			return
+		}
+		in.dragging = true
+		in.cx = mev.Xpos
+		in.cy = mev.Ypos
+	})
+	win.Subscribe(window.OnMouseUp, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		if mev.Button == window.MouseButtonLeft {
+			in.dragging = false
+		}
+	})
+	win.Subscribe(window.OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		if !in.dragging {
+			return
+		}
+		in.dx += cev.Xpos - in.cx
+		in.dy += cev.Ypos - in.cy
+		in.cx = cev.Xpos
+		in.cy = cev.Ypos
+	})
+	win.Subscribe(window.OnScroll, func(evname string, ev interface{}) {
+		sev := ev.(*window.ScrollEvent)
+		if enabled() {
+			in.scroll += sev.Yoffset
+		}
+	})
+	return in
+}
+
+// pressed returns if any of the specified keys is pressed
+func (in *cameraInput) pressed(keys ...window.Key) bool {
+
+	for _, key := range keys {
+		if in.keys[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// reset clears the mouse movement and scroll accumulated since the last frame
+func (in *cameraInput) reset() {
+
+	in.dx = 0
+	in.dy = 0
+	in.scroll = 0
+}
+
+// clear clears all the input state
+func (in *cameraInput) clear() {
+
+	in.reset()
+	in.keys = make(map[window.Key]bool)
+	in.dragging = false
+}
+
+// firstPersonControl is the controller for the fly and walk modes.
+// The camera moves with the WASD or arrow keys and looks around by dragging the mouse.
+// In fly mode Q/E or PageDown/PageUp move the camera down and up.
+// In walk mode the camera moves horizontally keeping its height above the ground.
+type firstPersonControl struct {
+	walk   bool                       // Walk mode
+	ground func(x, z float32) float32 // Ground height function used in walk mode
+	yaw    float32                    // Rotation around the vertical axis in radians
+	pitch  float32                    // Rotation above the horizontal plane in radians
+	dist   float32                    // Distance from the camera to its target
+	eye    float32                    // Eye height above the ground in walk mode
+	speed  float32                    // Movement speed in units per second
+}
+
+// start satisfies the cameraController interface
+func (fp *firstPersonControl) start(cam *camera.Camera) {
+
+	pos := cam.Position()
+	dir := cam.Target()
+	dir.Sub(&pos)
+	fp.dist = dir.Length()
+	if fp.dist == 0 {
+		dir = math32.Vector3{0, 0, -1}
+		fp.dist = 1
+	}
+	dir.Normalize()
+	fp.yaw = math32.Atan2(-dir.X, -dir.Z)
+	fp.pitch = math32.Asin(math32.Clamp(dir.Y, -1, 1))
+	// The speed is proportional to the target distance to suit the scene size
+	fp.speed = math32.Max(fp.dist, 1)
+	if fp.walk {
+		fp.eye = math32.Max(pos.Y-fp.ground(pos.X, pos.Z), camMinEyeHeight)
+	}
+}
+
+// update satisfies the cameraController interface
+func (fp *firstPersonControl) update(cam *camera.Camera, in *cameraInput, dt float32) {
+
+	// Mouse look and scroll to change the speed
+	fp.yaw -= in.dx * camLookSpeed
+	fp.pitch = math32.Clamp(fp.pitch-in.dy*camLookSpeed, -camMaxPitch, camMaxPitch)
+	if in.scroll != 0 {
+		fp.speed *= math32.Pow(1.2, in.scroll)
+	}
+
+	// Calculates the view direction and the movement axes
+	sy, cy := math32.Sin(fp.yaw), math32.Cos(fp.yaw)
+	sp, cp := math32.Sin(fp.pitch), math32.Cos(fp.pitch)
+	dir := math32.Vector3{-sy * cp, sp, -cy * cp}
+	forward := dir
+	if fp.walk {
+		forward = math32.Vector3{-sy, 0, -cy}
+	}
+	right := math32.Vector3{cy, 0, -sy}
+
+	// Moves the camera
+	var move math32.Vector3
+	if in.pressed(window.KeyW, window.KeyUp) {
+		move.Add(&forward)
+	}
+	if in.pressed(window.KeyS, window.KeyDown) {
+		move.Sub(&forward)
+	}
+	if in.pressed(window.KeyD, window.KeyRight) {
+		move.Add(&right)
+	}
+	if in.pressed(window.KeyA, window.KeyLeft) {
+		move.Sub(&right)
+	}
+	if !fp.walk && in.pressed(window.KeyE, window.KeyPageUp) {
+		move.Y++
+	}
+	if !fp.walk && in.pressed(window.KeyQ, window.KeyPageDown) {
+		move.Y--
+	}
+	pos := cam.Position()
+	if move.Length() > 0 {
+		step := fp.speed * dt
+		if in.pressed(window.KeyLeftShift, window.KeyRightShift) {
+			step *= camFastFactor
+		}
+		move.Normalize().MultiplyScalar(step)
+		pos.Add(&move)
+	}
+	if fp.walk {
+		pos.Y = fp.ground(pos.X, pos.Z) + fp.eye
+	}
+
+	// Updates the camera pose keeping the target distance
+	cam.SetPositionVec(&pos)
+	target := dir
+	target.MultiplyScalar(fp.dist).Add(&pos)
+	cam.LookAt(&target)
+}
+
+// turntableControl is the controller for the turntable mode.
+// The camera rotates around the vertical axis through its target by dragging the mouse
+// horizontally and changes its elevation by dragging vertically. The scroll wheel zooms.
+// After some time without user input the camera rotates automatically.
+type turntableControl struct {
+	target    math32.Vector3 // Center of rotation
+	azimuth   float32        // Rotation around the vertical axis in radians
+	elevation float32        // Angle above the horizontal plane in radians
+	radius    float32        // Distance from the camera to the target
+	idle      float32        // Time in seconds since the last user input
+}
+
+// start satisfies the cameraController interface
+func (tt *turntableControl) start(cam *camera.Camera) {
+
+	pos := cam.Position()
+	tt.target = cam.Target()
+	offset := pos
+	offset.Sub(&tt.target)
+	tt.radius = offset.Length()
+	if tt.radius == 0 {
+		offset = math32.Vector3{0, 0, 1}
+		tt.radius = 1
+	}
+	tt.azimuth = math32.Atan2(offset.X, offset.Z)
+	tt.elevation = math32.Asin(math32.Clamp(offset.Y/tt.radius, -1, 1))
+	tt.idle = 0
+}
+
+// update satisfies the cameraController interface
+func (tt *turntableControl) update(cam *camera.Camera, in *cameraInput, dt float32) {
+
+	if in.dragging || in.scroll != 0 {
+		tt.idle = 0
+	} else {
+		tt.idle += dt
+	}
+	tt.azimuth -= in.dx * camLookSpeed
+	tt.elevation = math32.Clamp(tt.elevation+in.dy*camLookSpeed, -camMaxPitch, camMaxPitch)
+	if in.scroll != 0 {
+		tt.radius *= math32.Pow(0.9, in.scroll)
+	}
+	if tt.idle > camTurntableDelay {
+		tt.azimuth += camTurntableSpin * dt
+	}
+
+	ce := math32.Cos(tt.elevation)
+	pos := math32.Vector3{
+		ce * math32.Sin(tt.azimuth),
+		math32.Sin(tt.elevation),
+		ce * math32.Cos(tt.azimuth),
+	}
+	pos.MultiplyScalar(tt.radius).Add(&tt.target)
+	cam.SetPositionVec(&pos)
+	cam.LookAt(&tt.target)
+}
+
+// SetGroundHeight sets the function which returns the ground height at the
+// specified horizontal position, used by the walk camera controller.
+// It is reset for each demo; the default ground height is zero.
+func (app *App) SetGroundHeight(f func(x, z float32) float32) {
+
+	app.groundHeight = f
+}
+
+// GroundHeight returns the ground height at the specified horizontal position
+func (app *App) GroundHeight(x, z float32) float32 {
+
+	if app.groundHeight == nil {
+		return 0
+	}
+	return app.groundHeight(x, z)
+}
+
+// setCameraMode sets the current camera controller mode.
+// The orbit control is kept for all modes because demos may access it,
+// but it is only enabled in the orbit mode.
+func (app *App) setCameraMode(mode string) {
+
+	prev := app.camCtl
+	app.camMode = mode
+	app.camInput.clear()
+	app.camStarted = false
+	switch mode {
+	case camFly:
+		app.camCtl = &firstPersonControl{}
+	case camWalk:
+		app.camCtl = &firstPersonControl{walk: true, ground: app.GroundHeight}
+	case camTurntable:
+		app.camCtl = &turntableControl{}
+	default:
+		app.camMode = camOrbit
+		app.camCtl = nil
+		// Recreates the orbit control so it uses the current camera pose
+		if prev != nil {
+			app.Orbit().Dispose()
+			app.SetOrbit(control.NewOrbitControl(app.Camera(), app.Window()))
+		}
+	}
+	app.Orbit().Enabled = app.camCtl == nil
+}
+
+// updateCamera updates the camera bookmark transition, the camera path
+// or the current camera controller, if any
+func (app *App) updateCamera() {
+
+	if app.camTrans != nil {
+		app.updateCameraTransition()
+		app.camInput.reset()
+		return
+	}
+	if app.updateCameraPath() {
+		app.camInput.reset()
+		return
+	}
+	if app.camCtl == nil {
+		return
+	}
+	cam := app.Camera().GetCamera()
+	if app.camStarted {
+		app.camCtl.update(cam, app.camInput, app.FrameDeltaSeconds())
+	} else {
+		app.camCtl.start(cam)
+		app.camStarted = true
+	}
+	app.camInput.reset()
+}
+
+// addCameraModeControl adds the camera controller selector to the control folder
+func (app *App) addCameraModeControl() {
+
+	app.control.AddPanel(gui.NewLabel("Camera control:"))
+	dd := gui.NewDropDown(app.Scaled(150), gui.NewImageLabel(""))
+	for _, mode := range camModes {
+		item := gui.NewImageLabel(mode)
+		dd.Add(item)
+		if mode == app.camMode {
+			dd.SetSelected(item)
+		}
+	}
+	dd.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		sel := dd.Selected()
+		if sel != nil && sel.Text() != app.camMode {
+			app.setCameraMode(sel.Text())
+		}
+	})
+	app.control.AddPanel(dd)
+}
@@ -0,0 +1,355 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+)
+
+// Command line option to play a camera path file after the demo starts and exit when it ends.
+// Useful for recording demo videos and for repeatable performance measurements.
+var oFlythrough = flag.String("flythrough", "", "Plays the specified camera path file in the demo and exits when it ends")
+
+// Camera path parameters in seconds
+const (
+	camPathRecordInterval = 0.25 // Interval between keys when recording
+	camPathKeyInterval    = 2    // Time between authored keys
+)
+
+// CameraKey is a camera pose at a time of a camera path
+type CameraKey struct {
+	Time float32 `json:"time"` // Time in seconds from the path start
+	CameraBookmark
+}
+
+// CameraPath is a sequence of camera poses which is played back
+// interpolating the poses with Catmull-Rom splines
+type CameraPath struct {
+	Keys []CameraKey `json:"keys"` // Keys sorted by time
+}
+
+// cameraPathState is the camera path of the current demo and its recording or playback state
+type cameraPathState struct {
+	demo      string      // Name of the demo of the path
+	path      *CameraPath // Current path
+	recording bool        // Recording the camera movement
+	playing   bool        // Playing the path
+	loop      bool        // Restart playback at the end
+	quit      bool        // Quit the application at the end of the playback
+	elapsed   float32     // Time since the start of the recording or playback
+	last      float32     // Time of the last recorded key
+}
+
+// Duration returns the duration of the path in seconds
+func (p *CameraPath) Duration() float32 {
+
+	if len(p.Keys) == 0 {
+		return 0
+	}
+	return p.Keys[len(p.Keys)-1].Time
+}
+
+// Pose returns the interpolated camera pose at the specified time
+func (p *CameraPath) Pose(t float32) CameraBookmark {
+
+	n := len(p.Keys)
+	if n == 0 {
+		return CameraBookmark{}
+	}
+	if t <= p.Keys[0].Time {
+		return p.Keys[0].CameraBookmark
+	}
+	if t >= p.Keys[n-1].Time {
+		return p.Keys[n-1].CameraBookmark
+	}
+
+	// Finds the segment which contains the time
+	i := 0
+	for i < n-2 && p.Keys[i+1].Time <= t {
+		i++
+	}
+	k1 := &p.Keys[i]
+	k2 := &p.Keys[i+1]
+	k0 := &p.Keys[imax(i-1, 0)]
+	k3 := &p.Keys[imin(i+2, n-1)]
+	u := float32(0)
+	if k2.Time > k1.Time {
+		u = (t - k1.Time) / (k2.Time - k1.Time)
+	}
+
+	var b CameraBookmark
+	b.Position = catmullRom(&k0.Position, &k1.Position, &k2.Position, &k3.Position, u)
+	b.Target = catmullRom(&k0.Target, &k1.Target, &k2.Target, &k3.Target, u)
+	b.Fov = k1.Fov + (k2.Fov-k1.Fov)*u
+	b.Zoom = k1.Zoom + (k2.Zoom-k1.Zoom)*u
+	return b
+}
+
+// catmullRom returns the point of the Catmull-Rom spline segment between p1 and p2 at u (0..1)
+func catmullRom(p0, p1, p2, p3 *math32.Vector3, u float32) math32.Vector3 {
+
+	u2 := u * u
+	u3 := u2 * u
+	f := func(a, b, c, d float32) float32 {
+		return 0.5 * (2*b + (-a+c)*u + (2*a-5*b+4*c-d)*u2 + (-a+3*b-3*c+d)*u3)
+	}
+	return math32.Vector3{
+		f(p0.X, p1.X, p2.X, p3.X),
+		f(p0.Y, p1.Y, p2.Y, p3.Y),
+		f(p0.Z, p1.Z, p2.Z, p3.Z),
+	}
+}
+
+// LoadCameraPath loads a camera path from the specified JSON file.
+// The keys are sorted by time and the path must have at least 2 keys
+// with different times.
+func LoadCameraPath(fpath string) (*CameraPath, error) {
+
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	p := new(CameraPath)
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	err = p.validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	return p, nil
+}
+
+// validate sorts the keys of the path by time and checks that the path
+// can be played: it has at least 2 keys and no keys with the same time
+func (p *CameraPath) validate() error {
+
+	if len(p.Keys) < 2 {
+		return fmt.Errorf("camera path has %d keys, at least 2 are required", len(p.Keys))
+	}
+	sort.SliceStable(p.Keys, func(i, j int) bool { return p.Keys[i].Time < p.Keys[j].Time })
+	for i := 1; i < len(p.Keys); i++ {
+		if p.Keys[i].Time <= p.Keys[i-1].Time {
+			return fmt.Errorf("camera path keys %d and %d have the same time %g", i-1, i, p.Keys[i].Time)
+		}
+	}
+	return nil
+}
+
+// Save saves the camera path to the specified JSON file
+func (p *CameraPath) Save(fpath string) error {
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(fpath), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, data, 0644)
+}
+
+// cameraPathFile returns the path of the camera path file of the specified demo,
+// which is saved in the user configuration directory
+func cameraPathFile(demo string) (string, error) {
+
+	fpath, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(fpath), "paths", demo+".json"), nil
+}
+
+// cameraPath returns the camera path state of the current demo.
+// The saved path of the demo is loaded when the demo changes.
+func (app *App) cameraPath() *cameraPathState {
+
+	cp := app.camPath
+	if cp != nil && cp.demo == app.currentName {
+		return cp
+	}
+	cp = &cameraPathState{demo: app.currentName, path: new(CameraPath)}
+	app.camPath = cp
+	fpath, err := cameraPathFile(app.currentName)
+	if err != nil {
+		return cp
+	}
+	if _, err := os.Stat(fpath); err == nil {
+		app.loadCameraPath(fpath)
+	}
+	return cp
+}
+
+// loadCameraPath loads the camera path of the current demo from the specified file.
+// Errors are logged and returned.
+func (app *App) loadCameraPath(fpath string) error {
+
+	p, err := LoadCameraPath(fpath)
+	if err != nil {
+		app.log.Error("Error loading camera path:%v", err)
+		return err
+	}
+	app.camPath.path = p
+	app.log.Info("Loaded camera path with %d keys from:%s", len(p.Keys), fpath)
+	return nil
+}
+
+// saveCameraPath saves the camera path of the current demo in the user configuration directory
+func (app *App) saveCameraPath() {
+
+	if app.currentName == "" {
+		app.log.Error("No demo selected")
+		return
+	}
+	fpath, err := cameraPathFile(app.currentName)
+	if err == nil {
+		err = app.cameraPath().path.Save(fpath)
+	}
+	if err != nil {
+		app.log.Error("Error saving camera path:%v", err)
+		return
+	}
+	app.log.Info("Saved camera path to:%s", fpath)
+}
+
+// startRecording clears the camera path and starts recording the camera movement
+func (app *App) startRecording() {
+
+	cp := app.cameraPath()
+	cp.playing = false
+	cp.recording = true
+	cp.elapsed = 0
+	cp.path = new(CameraPath)
+	app.recordCameraKey(0)
+	app.log.Info("Recording camera path")
+}
+
+// addCameraKey appends the current camera pose to the camera path
+func (app *App) addCameraKey() {
+
+	cp := app.cameraPath()
+	t := float32(0)
+	if len(cp.path.Keys) > 0 {
+		t = cp.path.Duration() + camPathKeyInterval
+	}
+	app.recordCameraKey(t)
+}
+
+// recordCameraKey appends the current camera pose at the specified time to the camera path
+func (app *App) recordCameraKey(t float32) {
+
+	cp := app.cameraPath()
+	cp.path.Keys = append(cp.path.Keys, CameraKey{Time: t, CameraBookmark: app.cameraPose()})
+	cp.last = t
+}
+
+// playCameraPath starts playing the camera path of the current demo
+func (app *App) playCameraPath(loop bool) {
+
+	cp := app.cameraPath()
+	if len(cp.path.Keys) < 2 {
+		app.log.Error("Camera path has less than 2 keys")
+		return
+	}
+	cp.recording = false
+	cp.playing = true
+	cp.loop = loop
+	cp.elapsed = 0
+}
+
+// stopCameraPath stops the recording or playback of the camera path
+func (app *App) stopCameraPath() {
+
+	cp := app.cameraPath()
+	if cp.recording {
+		app.log.Info("Recorded camera path with %d keys", len(cp.path.Keys))
+	}
+	cp.recording = false
+	cp.playing = false
+	app.camStarted = false
+}
+
+// updateCameraPath records the camera pose or updates the camera pose from
+// the camera path being played. Returns true if the path is being played.
+func (app *App) updateCameraPath() bool {
+
+	cp := app.camPath
+	if cp == nil || cp.demo != app.currentName || (!cp.recording && !cp.playing) {
+		return false
+	}
+	cp.elapsed += app.FrameDeltaSeconds()
+	if cp.recording {
+		if cp.elapsed-cp.last >= camPathRecordInterval {
+			app.recordCameraKey(cp.elapsed)
+		}
+		return false
+	}
+
+	if cp.elapsed > cp.path.Duration() {
+		if cp.loop {
+			cp.elapsed = 0
+		} else {
+			app.stopCameraPath()
+			if cp.quit {
+				app.Quit()
+			}
+			return false
+		}
+	}
+	b := cp.path.Pose(cp.elapsed)
+	cam := app.Camera().GetCamera()
+	cam.SetPositionVec(&b.Position)
+	cam.LookAt(&b.Target)
+	switch c := app.Camera().(type) {
+	case *camera.Perspective:
+		if b.Fov > 0 {
+			c.SetFov(b.Fov)
+		}
+	case *camera.Orthographic:
+		if b.Zoom > 0 {
+			c.SetZoom(b.Zoom)
+		}
+	}
+	return true
+}
+
+// startFlythrough plays the camera path file specified in the command line
+// and quits the application when it ends. Exits with status 1 if the file
+// cannot be loaded or the path cannot be played.
+func (app *App) startFlythrough() {
+
+	if *oFlythrough == "" {
+		return
+	}
+	app.cameraPath()
+	if app.loadCameraPath(*oFlythrough) != nil {
+		os.Exit(1)
+	}
+	app.playCameraPath(false)
+	app.camPath.quit = true
+}
+
+func imin(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func imax(a, b int) int {
+
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a subcommand which runs without opening the application window
+type command struct {
+	usage string                                              // Command usage line
+	run   func(args []string, demoMap map[string]IDemo) error // Command function
+}
+
+// commands maps the subcommand names to their functions
+var commands = map[string]*command{
+	"new": {"new [-controls] [-nolights] <category>.<name>", cmdNew},
+}
+
+// RegisterCommand registers a subcommand with the specified name, usage line and function,
+// for commands implemented by the demo packages. Must be called from an init function.
+func RegisterCommand(name, usage string, run func(args []string, demoMap map[string]IDemo) error) {
+
+	commands[name] = &command{usage, run}
+}
+
+// runCommand checks if the first command line argument is a subcommand and runs it.
+// Returns false if no subcommand was specified.
+// Flags are checked directly in os.Args because they are only parsed
+// by the standard application object, which opens a window.
+func runCommand(demoMap map[string]IDemo) bool {
+
+	if len(os.Args) < 2 {
+		return false
+	}
+	cmd := commands[os.Args[1]]
+	if cmd == nil {
+		return false
+	}
+	err := cmd.run(os.Args[2:], demoMap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", execName, err)
+		os.Exit(1)
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Config contains the user settings which are persisted between executions
+type Config struct {
+	Theme     string `json:"theme,omitempty"`     // Name of the GUI theme
+	StyleFile string `json:"styleFile,omitempty"` // Path of the custom GUI style file
+
+	// Camera bookmarks for slots 1 to 9 keyed by demo name
+	Bookmarks map[string][]*CameraBookmark `json:"bookmarks,omitempty"`
+}
+
+// configPath returns the path of the user configuration file
+func configPath() (string, error) {
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, execName, "config.json"), nil
+}
+
+// loadConfig loads the user configuration file.
+// Returns an empty configuration if the file does not exist.
+func loadConfig() (*Config, error) {
+
+	cfg := new(Config)
+	fpath, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := ioutil.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(data, cfg)
+	return cfg, err
+}
+
+// saveConfig saves the current user configuration
+func (app *App) saveConfig() {
+
+	fpath, err := configPath()
+	if err != nil {
+		app.log.Error("Error saving configuration:%v", err)
+		return
+	}
+	data, err := json.MarshalIndent(app.config, "", "  ")
+	if err != nil {
+		app.log.Error("Error saving configuration:%v", err)
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(fpath), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(fpath, data, 0644)
+	}
+	if err != nil {
+		app.log.Error("Error saving configuration:%v", err)
+	}
+}
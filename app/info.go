@@ -0,0 +1,193 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DemoInfo describes a demo for the listing and inspection command line options
+type DemoInfo struct {
+	Description  string   `json:"description"`            // Short description of the demo
+	Assets       []string `json:"assets,omitempty"`       // Data files or directories required, relative to the data directory
+	Capabilities []string `json:"capabilities,omitempty"` // Engine and system features used by the demo
+}
+
+// demoListItem is the information about a demo printed by -list and -info
+type demoListItem struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	DemoInfo
+	Missing []string `json:"missing,omitempty"` // Required assets not found in the data directory
+}
+
+// Command line options for listing and inspecting demos.
+// They are checked before the application window is created.
+var (
+	oList = flag.Bool("list", false, "Lists all demos with their categories and descriptions and exits")
+	oJSON = flag.Bool("json", false, "Prints the output of -list, -info and -validate in JSON format")
+	oInfo = flag.String("info", "", "Prints the required assets and capabilities of the specified demo and exits")
+)
+
+// runInfo checks for the -list and -info command line options and executes them.
+// Returns false if none of these options were specified.
+func runInfo(demoMap map[string]IDemo, demoInfo map[string]*DemoInfo) bool {
+
+	_, list := lookupArg("list", true)
+	info, hasInfo := lookupArg("info", false)
+	if !list && !hasInfo {
+		return false
+	}
+	_, useJSON := lookupArg("json", true)
+
+	var err error
+	if list {
+		err = listDemos(demoMap, demoInfo, useJSON)
+	} else {
+		err = printDemoInfo(demoMap, demoInfo, info, useJSON)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", execName, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// listDemos prints all the demos sorted by name
+func listDemos(demoMap map[string]IDemo, demoInfo map[string]*DemoInfo, useJSON bool) error {
+
+	names := []string{}
+	for name := range demoMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := []*demoListItem{}
+	for _, name := range names {
+		items = append(items, newDemoListItem(name, demoInfo[name]))
+	}
+	if useJSON {
+		return printJSON(items)
+	}
+
+	// Prints demos grouped by category
+	category := ""
+	for _, item := range items {
+		if item.Category != category {
+			category = item.Category
+			fmt.Printf("%s\n", category)
+		}
+		fmt.Printf("  %-36s %s\n", item.Name, item.Description)
+	}
+	return nil
+}
+
+// printDemoInfo prints the information about the specified demo
+func printDemoInfo(demoMap map[string]IDemo, demoInfo map[string]*DemoInfo, name string, useJSON bool) error {
+
+	if _, ok := demoMap[name]; !ok {
+		return fmt.Errorf("invalid demo name:%s", name)
+	}
+	item := newDemoListItem(name, demoInfo[name])
+
+	// Checks for missing assets if the data directory is found
+	dirData, err := findDirData("data")
+	if err != nil {
+		return err
+	}
+	if dirData != "" {
+		for _, asset := range item.Assets {
+			if _, err := os.Stat(filepath.Join(dirData, asset)); err != nil {
+				item.Missing = append(item.Missing, asset)
+			}
+		}
+	}
+	if useJSON {
+		return printJSON(item)
+	}
+
+	fmt.Printf("Name:         %s\n", item.Name)
+	fmt.Printf("Category:     %s\n", item.Category)
+	fmt.Printf("Description:  %s\n", item.Description)
+	fmt.Printf("Capabilities: %s\n", strings.Join(item.Capabilities, ", "))
+	fmt.Printf("Assets:\n")
+	missing := make(map[string]bool)
+	for _, asset := range item.Missing {
+		missing[asset] = true
+	}
+	for _, asset := range item.Assets {
+		if missing[asset] {
+			fmt.Printf("  %s (MISSING)\n", asset)
+		} else {
+			fmt.Printf("  %s\n", asset)
+		}
+	}
+	if dirData == "" {
+		fmt.Printf("Data directory not found: assets not checked\n")
+	}
+	return nil
+}
+
+// newDemoListItem creates the list item for the specified demo name and optional info
+func newDemoListItem(name string, info *DemoInfo) *demoListItem {
+
+	item := &demoListItem{Name: name}
+	parts := strings.Split(name, ".")
+	if len(parts) > 1 {
+		item.Category = parts[0]
+	}
+	if info != nil {
+		item.DemoInfo = *info
+	}
+	return item
+}
+
+// printJSON prints the specified value as indented JSON
+func printJSON(v interface{}) error {
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+// lookupArg checks if the specified flag is present in the command line arguments
+// and returns its value. For boolean flags only the presence is checked unless
+// the value is specified as -flag=value.
+func lookupArg(name string, isBool bool) (string, bool) {
+
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimLeft(arg, "-")
+		parts := strings.SplitN(arg, "=", 2)
+		if parts[0] != name {
+			continue
+		}
+		if len(parts) == 2 {
+			if isBool && parts[1] == "false" {
+				return "", false
+			}
+			return parts[1], true
+		}
+		if isBool {
+			return "", true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+		return "", true
+	}
+	return "", false
+}
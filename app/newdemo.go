@@ -0,0 +1,231 @@
+package app
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// modulePath is the import path of the g3nd module
+const modulePath = "github.com/g3n/g3nd"
+
+// validName matches valid demo category and name parts
+var validName = regexp.MustCompile(`^\|?[a-z][a-z0-9_]*\|?$`)
+
+// demoTemplateData contains the parameters of the demo source template
+type demoTemplateData struct {
+	Package  string // Go package name
+	Key      string // Demo key in demos.Map
+	TypeName string // Demo type name
+	Controls bool   // Adds control folder group
+	Lights   bool   // Adds lights
+}
+
+// demoTemplate is the template for new demo source files
+var demoTemplate = template.Must(template.New("demo").Parse(`package {{.Package}}
+
+import (
+{{- if .Controls}}
+	"github.com/g3n/engine/gui"
+{{- end}}
+	"github.com/g3n/engine/graphic"
+{{- if .Lights}}
+	"github.com/g3n/engine/light"
+{{- end}}
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/demos"
+)
+
+func init() {
+	demos.Map["{{.Key}}"] = &{{.TypeName}}{}
+}
+
+type {{.TypeName}} struct {
+{{- if .Controls}}
+	rotate bool // Rotate the grid
+{{- end}}
+	grid *graphic.GridHelper
+}
+
+func (t *{{.TypeName}}) Initialize(a *app.App) {
+
+	// Show axis helper
+	ah := graphic.NewAxisHelper(1.0)
+	a.Scene().Add(ah)
+
+	// Creates a grid helper
+	t.grid = graphic.NewGridHelper(50, 1, &math32.Color{0.4, 0.4, 0.4})
+	a.Scene().Add(t.grid)
+{{- if .Lights}}
+
+	// Adds white directional front light
+	l1 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	l1.SetPosition(0, 0, 10)
+	a.Scene().Add(l1)
+
+	// Adds white directional top light
+	l2 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	l2.SetPosition(0, 10, 0)
+	a.Scene().Add(l2)
+{{- end}}
+
+	// Sets camera position
+	a.Camera().GetCamera().SetPosition(0, 4, 10)
+	a.Camera().GetCamera().LookAt(&math32.Vector3{0, 0, 0})
+{{- if .Controls}}
+
+	// Add controls
+	if a.ControlFolder() == nil {
+		return
+	}
+	t.rotate = true
+	g1 := a.ControlFolder().AddGroup("{{.Key}}")
+	cb1 := g1.AddCheckBox("Rotate").SetValue(true)
+	cb1.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		t.rotate = cb1.Value()
+	})
+{{- end}}
+}
+
+func (t *{{.TypeName}}) Render(a *app.App) {
+{{if .Controls}}
+	if !t.rotate {
+		return
+	}
+{{- end}}
+	rps := a.FrameDeltaSeconds() * 2 * math32.Pi
+	t.grid.RotateY(rps * 0.05)
+}
+`))
+
+// cmdNew creates the source file of a new demo from a template and
+// adds the demo package import to main.go if necessary.
+// It must be executed from the g3nd source directory.
+func cmdNew(args []string, demoMap map[string]IDemo) error {
+
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	controls := fs.Bool("controls", false, "Adds a control folder group to the demo")
+	nolights := fs.Bool("nolights", false, "Do not add lights to the demo")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: %s %s", execName, commands["new"].usage)
+	}
+
+	// Checks demo key
+	key := fs.Arg(0)
+	parts := strings.Split(key, ".")
+	if len(parts) != 2 || !validName.MatchString(parts[0]) || !validName.MatchString(parts[1]) {
+		return fmt.Errorf("invalid demo name:%q (expected <category>.<name> in lower case)", key)
+	}
+	if _, ok := demoMap[key]; ok {
+		return fmt.Errorf("demo already exists:%s", key)
+	}
+	category := strings.Trim(parts[0], "|")
+	name := strings.Trim(parts[1], "|")
+
+	// Checks that the current directory is the g3nd source directory
+	if _, err := os.Stat("main.go"); err != nil {
+		return fmt.Errorf("main.go not found: run this command from the g3nd source directory")
+	}
+	if _, err := os.Stat("demos"); err != nil {
+		return fmt.Errorf("demos directory not found: run this command from the g3nd source directory")
+	}
+
+	// Generates demo source
+	data := demoTemplateData{
+		Package:  category,
+		Key:      key,
+		TypeName: camelCase(category) + camelCase(name),
+		Controls: *controls,
+		Lights:   !*nolights,
+	}
+	var buf bytes.Buffer
+	err = demoTemplate.Execute(&buf, &data)
+	if err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Writes demo source file in the category package directory
+	err = os.MkdirAll(category, 0755)
+	if err != nil {
+		return err
+	}
+	fpath := filepath.Join(category, name+".go")
+	if _, err := os.Stat(fpath); err == nil {
+		return fmt.Errorf("file already exists:%s", fpath)
+	}
+	err = ioutil.WriteFile(fpath, src, 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s\n", fpath)
+
+	// Adds package import to main.go
+	added, err := addMainImport(modulePath + "/" + category)
+	if err != nil {
+		return err
+	}
+	if added {
+		fmt.Printf("Added import of package %s to main.go\n", category)
+	}
+	fmt.Printf("Add the demo description to demos/info.go\n")
+	return nil
+}
+
+// addMainImport adds a blank import of the specified package to main.go
+// after the last demo package import, if not already imported.
+func addMainImport(pkg string) (bool, error) {
+
+	src, err := ioutil.ReadFile("main.go")
+	if err != nil {
+		return false, err
+	}
+	quoted := `"` + pkg + `"`
+	if bytes.Contains(src, []byte(quoted)) {
+		return false, nil
+	}
+	lines := strings.Split(string(src), "\n")
+	last := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), `_ "`+modulePath+"/") {
+			last = i
+		}
+	}
+	if last < 0 {
+		return false, fmt.Errorf("no demo package imports found in main.go")
+	}
+	lines = append(lines[:last+1], append([]string{"\t_ " + quoted}, lines[last+1:]...)...)
+	err = ioutil.WriteFile("main.go", []byte(strings.Join(lines, "\n")), 0644)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// camelCase converts a lower case name with optional underscores to camel case
+func camelCase(name string) string {
+
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"flag"
+	"math"
+
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/g3nd/util"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Command line option for the GUI scale factor
+var oUIScale = flag.Float64("uiscale", 0, "Sets the GUI scale factor. If zero it is detected from the monitor resolution")
+
+// Limits of the detected GUI scale factor
+const (
+	minUIScale = 1
+	maxUIScale = 4
+	refDPI     = 96 // Resolution of a monitor with scale factor 1
+)
+
+// UIScale returns the current GUI scale factor
+func (app *App) UIScale() float32 {
+
+	return app.uiScale
+}
+
+// Scaled returns the specified GUI size in pixels multiplied by the GUI scale factor
+func (app *App) Scaled(size float32) float32 {
+
+	return size * app.uiScale
+}
+
+// initUIScale sets the GUI scale factor from the command line or
+// detects it from the primary monitor resolution.
+func (app *App) initUIScale() {
+
+	app.uiScale = float32(*oUIScale)
+	if app.uiScale <= 0 {
+		app.uiScale = detectUIScale()
+	}
+	app.log.Info("Using GUI scale factor:%v", app.uiScale)
+	util.SetUIScale(app.uiScale)
+}
+
+// scaleStyle scales the fonts of the specified style by the GUI scale factor
+func (app *App) scaleStyle(style *gui.Style) {
+
+	const fontDPI = 72
+	for _, font := range []*text.Font{style.Font, style.FontIcon} {
+		if font != nil {
+			font.SetDPI(fontDPI * float64(app.uiScale))
+		}
+	}
+}
+
+// detectUIScale returns the scale factor for the primary monitor resolution,
+// rounded to multiples of 0.25, or 1 if the resolution cannot be determined.
+func detectUIScale() float32 {
+
+	monitor := glfw.GetPrimaryMonitor()
+	if monitor == nil {
+		return minUIScale
+	}
+	mode := monitor.GetVideoMode()
+	widthMM, _ := monitor.GetPhysicalSize()
+	if mode == nil || widthMM <= 0 {
+		return minUIScale
+	}
+	dpi := float32(mode.Width) / (float32(widthMM) / 25.4)
+	scale := float32(math.Floor(float64(dpi/refDPI*4)+0.5)) / 4
+	return math32.Clamp(scale, minUIScale, maxUIScale)
+}
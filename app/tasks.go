@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Default time budget per frame for the functions queued to run in the main thread
+const defaultTaskBudget = 4 * time.Millisecond
+
+// taskQueue is a list of functions queued by other goroutines
+// to be executed in the main thread
+type taskQueue struct {
+	mu     sync.Mutex
+	tasks  []mainTask
+	budget time.Duration      // Maximum time per frame to execute tasks
+	ctx    context.Context    // Context of the current demo
+	cancel context.CancelFunc // Cancels the context of the current demo
+}
+
+// mainTask is a function queued to be executed in the main thread
+// unless its context is cancelled first
+type mainTask struct {
+	ctx context.Context
+	f   func()
+}
+
+// Future is the result of a function executed in the background with Async.
+// Its state is only accessed in the main thread.
+type Future struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      bool                       // Result is available or the future was cancelled
+	cancelled bool                       // Future was cancelled
+	result    interface{}                // Result of the function
+	err       error                      // Error returned by the function
+	callbacks []func(interface{}, error) // Functions to call with the result
+}
+
+// RunOnMain queues the specified function to be executed in the main thread
+// before a next frame is rendered. It is safe to call from any goroutine and
+// must be used by background work to access the scene, the GUI and OpenGL.
+// Queued functions are executed in order, as many per frame as fit in the
+// task budget. Functions still queued when the demo is torn down are discarded.
+// Goroutines which may outlive their demo should use RunOnMainContext.
+func (app *App) RunOnMain(f func()) {
+
+	app.RunOnMainContext(app.DemoContext(), f)
+}
+
+// RunOnMainContext queues the specified function as RunOnMain,
+// unless the specified context is cancelled before it is executed.
+// Background work of a demo must pass the context returned by DemoContext when the
+// work was started, or a context derived from it such as the one received from Async,
+// so functions queued after the demo is torn down are discarded and never access
+// the scene of the next demo.
+func (app *App) RunOnMainContext(ctx context.Context, f func()) {
+
+	app.taskQueue.mu.Lock()
+	app.taskQueue.tasks = append(app.taskQueue.tasks, mainTask{ctx, f})
+	app.taskQueue.mu.Unlock()
+}
+
+// SetTaskBudget sets the maximum time per frame used to execute the functions
+// queued with RunOnMain. At least one function is executed per frame.
+func (app *App) SetTaskBudget(budget time.Duration) {
+
+	app.taskQueue.budget = budget
+}
+
+// DemoContext returns a context which is cancelled when the current demo is torn down
+func (app *App) DemoContext() context.Context {
+
+	app.taskQueue.mu.Lock()
+	defer app.taskQueue.mu.Unlock()
+	return app.taskQueue.ctx
+}
+
+// Async executes the specified function in a new goroutine and returns the future of its result.
+// The function receives a context which is cancelled when the future is cancelled or when
+// the current demo is torn down, in which case the future callbacks are never called.
+// A panic in the function is recovered and returned as the error of the future.
+// Must be called in the main thread.
+func (app *App) Async(f func(ctx context.Context) (interface{}, error)) *Future {
+
+	fut := new(Future)
+	fut.ctx, fut.cancel = context.WithCancel(app.DemoContext())
+	go func() {
+		result, err := app.runRecovered(fut.ctx, f)
+		app.RunOnMainContext(fut.ctx, func() { fut.resolve(result, err) })
+	}()
+	return fut
+}
+
+// runRecovered calls the specified function of Async and converts a panic into an error,
+// as a panic in a background goroutine would terminate the application
+func (app *App) runRecovered(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (result interface{}, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			app.log.Error("panic in background task: %v\n%s", r, debug.Stack())
+			result, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f(ctx)
+}
+
+// Then adds a function to be called in the main thread with the result of the future.
+// If the result is already available the function is called immediately.
+func (fut *Future) Then(f func(result interface{}, err error)) *Future {
+
+	if fut.done {
+		if !fut.cancelled {
+			f(fut.result, fut.err)
+		}
+		return fut
+	}
+	fut.callbacks = append(fut.callbacks, f)
+	return fut
+}
+
+// Cancel cancels the future: its context is cancelled and its callbacks will not be called
+func (fut *Future) Cancel() {
+
+	if fut.done {
+		return
+	}
+	fut.done = true
+	fut.cancelled = true
+	fut.callbacks = nil
+	fut.cancel()
+}
+
+// Done returns if the result of the future is available or if it was cancelled
+func (fut *Future) Done() bool {
+
+	return fut.done
+}
+
+// Cancelled returns if the future was cancelled
+func (fut *Future) Cancelled() bool {
+
+	return fut.cancelled
+}
+
+// Result returns the result and error of the function of a done future
+func (fut *Future) Result() (interface{}, error) {
+
+	return fut.result, fut.err
+}
+
+// resolve sets the result of the future and calls its callbacks,
+// unless it was cancelled
+func (fut *Future) resolve(result interface{}, err error) {
+
+	if fut.done {
+		return
+	}
+	if fut.ctx.Err() != nil {
+		fut.Cancel()
+		return
+	}
+	fut.done = true
+	fut.result = result
+	fut.err = err
+	callbacks := fut.callbacks
+	fut.callbacks = nil
+	for _, f := range callbacks {
+		f(result, err)
+	}
+	fut.cancel()
+}
+
+// runTasks executes the queued functions in the order they were queued
+// until the queue is empty or the task budget is used.
+// Functions whose context was cancelled are discarded.
+func (app *App) runTasks() {
+
+	start := time.Now()
+	for {
+		app.taskQueue.mu.Lock()
+		if len(app.taskQueue.tasks) == 0 {
+			app.taskQueue.mu.Unlock()
+			return
+		}
+		task := app.taskQueue.tasks[0]
+		app.taskQueue.tasks[0] = mainTask{}
+		app.taskQueue.tasks = app.taskQueue.tasks[1:]
+		app.taskQueue.mu.Unlock()
+		if task.ctx.Err() != nil {
+			continue
+		}
+		task.f()
+		if time.Since(start) >= app.taskQueue.budget {
+			return
+		}
+	}
+}
+
+// resetTasks cancels the context of the current demo, which cancels its futures,
+// discards the queued functions and creates the context for the next demo
+func (app *App) resetTasks() {
+
+	app.taskQueue.mu.Lock()
+	defer app.taskQueue.mu.Unlock()
+	if app.taskQueue.cancel != nil {
+		app.taskQueue.cancel()
+	}
+	app.taskQueue.tasks = nil
+	app.taskQueue.ctx, app.taskQueue.cancel = context.WithCancel(context.Background())
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/g3nd/util"
+)
+
+// Textures returns textures for the specified image files and import options, such as the
+// textures of a material, from the asset cache. The images which are not cached are decoded
+// concurrently by a pool of workers and the decoding time of each file is logged.
+// The textures are created and cached in the main thread. Must be called in the main thread.
+func (app *App) Textures(paths []string, opts *TextureOptions) ([]*texture.Texture2D, error) {
+
+	if opts == nil {
+		opts = &TextureOptions{}
+	}
+
+	// Decodes the images not found in the cache
+	var missing []string
+	for _, path := range paths {
+		if !app.cache.cached(textureKey(path, opts)) {
+			missing = append(missing, path)
+		}
+	}
+	imgs, err := app.decodeImages(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates the textures
+	texs := make([]*texture.Texture2D, 0, len(paths))
+	for _, path := range paths {
+		tex, err := app.cachedTexture(path, opts, imgs[path])
+		if err != nil {
+			for _, t := range texs {
+				t.Dispose()
+			}
+			return nil, err
+		}
+		texs = append(texs, tex)
+	}
+	return texs, nil
+}
+
+// Skybox creates and returns a skybox with the six face images specified as in the engine
+// skybox data, decoding the images concurrently and sharing them through the asset cache
+func (app *App) Skybox(data graphic.SkyboxData) (*graphic.Skybox, error) {
+
+	paths := make([]string, 0, len(data.Suffixes))
+	for _, suffix := range data.Suffixes {
+		paths = append(paths, data.DirAndPrefix+suffix+"."+data.Extension)
+	}
+	texs, err := app.Textures(paths, nil)
+	if err != nil {
+		return nil, err
+	}
+	var faces [6]*texture.Texture2D
+	copy(faces[:], texs)
+	return util.NewSkybox(faces)
+}
+
+// decodeImages decodes the specified image files concurrently with one worker per CPU
+// and returns the decoded images by path. Returns the first error found.
+func (app *App) decodeImages(paths []string) (map[string]*image.RGBA, error) {
+
+	imgs := make(map[string]*image.RGBA)
+	if len(paths) == 0 {
+		return imgs, nil
+	}
+	workers := runtime.NumCPU()
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	type decoded struct {
+		path string
+		img  *image.RGBA
+		err  error
+	}
+	jobs := make(chan string)
+	results := make(chan decoded, len(paths))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				start := time.Now()
+				img, err := texture.DecodeImage(path)
+				if err == nil {
+					app.log.Debug("Decoded image:%s (%dx%d) in %v", path, img.Bounds().Dx(), img.Bounds().Dy(), time.Since(start))
+				}
+				results <- decoded{path, img, err}
+			}
+		}()
+	}
+	start := time.Now()
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+	app.log.Debug("Decoded %d images with %d workers in %v", len(paths), workers, time.Since(start))
+
+	var err error
+	for res := range results {
+		if res.err != nil {
+			if err == nil {
+				err = res.err
+			}
+			continue
+		}
+		imgs[res.path] = res.img
+	}
+	return imgs, err
+}
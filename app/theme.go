@@ -0,0 +1,243 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"gopkg.in/yaml.v2"
+)
+
+// Theme contains the GUI style and the colors of the application shell
+type Theme struct {
+	Name       string        // Theme name
+	Style      *gui.Style    // Style for all GUI elements
+	Header     math32.Color4 // Header background color
+	HeaderText math32.Color4 // Header text color
+}
+
+// Names of the built-in themes
+const (
+	themeLight        = "light"
+	themeDark         = "dark"
+	themeHighContrast = "high-contrast"
+	themeCustom       = "custom"
+)
+
+// themeNames contains the names of the built-in themes in the order shown in the header
+var themeNames = []string{themeLight, themeDark, themeHighContrast}
+
+// highContrastStyle contains the overrides of the dark style for the high contrast theme.
+// It uses the same format as custom style files.
+const highContrastStyle = `
+header: {r: 0, g: 0, b: 0, a: 1}
+headerText: {r: 1, g: 1, b: 0, a: 1}
+style:
+  color:
+    bgdark: {r: 0, g: 0, b: 0, a: 1}
+    bgmed: {r: 0, g: 0, b: 0, a: 1}
+    bgnormal: {r: 0, g: 0, b: 0, a: 1}
+    bgover: {r: 0.2, g: 0.2, b: 0.2, a: 1}
+    highlight: {r: 1, g: 1, b: 0, a: 1}
+    select: {r: 0, g: 0.3, b: 1, a: 1}
+    text: {r: 1, g: 1, b: 1, a: 1}
+    textdis: {r: 0.7, g: 0.7, b: 0.7, a: 1}
+  label:
+    fgcolor: {r: 1, g: 1, b: 1, a: 1}
+  button:
+    normal: {fgcolor: {r: 1, g: 1, b: 1, a: 1}, bgcolor: {r: 0, g: 0, b: 0, a: 1}, bordercolor: {r: 1, g: 1, b: 1, a: 1}}
+    over: {fgcolor: {r: 1, g: 1, b: 0, a: 1}, bgcolor: {r: 0.2, g: 0.2, b: 0.2, a: 1}, bordercolor: {r: 1, g: 1, b: 0, a: 1}}
+    focus: {fgcolor: {r: 1, g: 1, b: 0, a: 1}, bgcolor: {r: 0.2, g: 0.2, b: 0.2, a: 1}, bordercolor: {r: 1, g: 1, b: 0, a: 1}}
+    pressed: {fgcolor: {r: 0, g: 0, b: 0, a: 1}, bgcolor: {r: 1, g: 1, b: 0, a: 1}, bordercolor: {r: 1, g: 1, b: 0, a: 1}}
+  tree:
+    list:
+      item:
+        normal: {fgcolor: {r: 1, g: 1, b: 1, a: 1}, bgcolor: {r: 0, g: 0, b: 0, a: 1}}
+        over: {fgcolor: {r: 0, g: 0, b: 0, a: 1}, bgcolor: {r: 1, g: 1, b: 0, a: 1}}
+        selected: {fgcolor: {r: 1, g: 1, b: 1, a: 1}, bgcolor: {r: 0, g: 0.3, b: 1, a: 1}}
+    node:
+      normal: {fgcolor: {r: 1, g: 1, b: 0, a: 1}, bgcolor: {r: 0, g: 0, b: 0, a: 1}}
+`
+
+// themeFile is the format of custom style files (YAML).
+// The style field overrides the fields of the gui.Style of the base theme
+// using their lower case names. The fields of the styles embedded in other
+// styles, as the background color of the button styles, are set as fields
+// of the embedding style, for example:
+//
+//	base: dark
+//	header: {r: 0.1, g: 0.1, b: 0.1, a: 1}
+//	headerText: {r: 1, g: 1, b: 1, a: 1}
+//	style:
+//	  label:
+//	    fgcolor: {r: 1, g: 1, b: 0, a: 1}
+//	  button:
+//	    normal: {bgcolor: {r: 0, g: 0, b: 0, a: 1}}
+type themeFile struct {
+	Base       string                      `yaml:"base"`
+	Header     *math32.Color4              `yaml:"header"`
+	HeaderText *math32.Color4              `yaml:"headerText"`
+	Style      map[interface{}]interface{} `yaml:"style"`
+}
+
+// newTheme creates and returns the built-in theme with the specified name
+func newTheme(name string) (*Theme, error) {
+
+	t := &Theme{Name: name}
+	switch name {
+	case themeLight:
+		t.Style = gui.NewLightStyle()
+		t.Header = math32.Color4{13.0 / 256.0, 41.0 / 256.0, 62.0 / 256.0, 1}
+		t.HeaderText = math32.Color4{0.8, 0.8, 0.8, 1}
+	case themeDark:
+		t.Style = gui.NewDarkStyle()
+		t.Header = math32.Color4{0.15, 0.15, 0.15, 1}
+		t.HeaderText = math32.Color4{0.8, 0.8, 0.8, 1}
+	case themeHighContrast:
+		t.Style = gui.NewDarkStyle()
+		err := t.override([]byte(highContrastStyle))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid theme name:%s", name)
+	}
+	return t, nil
+}
+
+// loadTheme loads a custom theme from the specified style file
+func loadTheme(fpath string) (*Theme, error) {
+
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decodes the base theme name first
+	var tf themeFile
+	err = yaml.Unmarshal(data, &tf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	if tf.Base == "" {
+		tf.Base = themeLight
+	}
+	t, err := newTheme(tf.Base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	t.Name = themeCustom
+	err = t.override(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	return t, nil
+}
+
+// override decodes the specified style file contents over the theme colors and style
+func (t *Theme) override(data []byte) error {
+
+	tf := themeFile{Header: &t.Header, HeaderText: &t.HeaderText}
+	err := yaml.Unmarshal(data, &tf)
+	if err != nil {
+		return err
+	}
+	return overrideFields(reflect.ValueOf(t.Style).Elem(), tf.Style, "style")
+}
+
+// overrideFields sets the fields of the specified struct from the values of the specified
+// YAML map by their lower case names, keeping the fields which are not in the map.
+// The YAML decoder only decodes the fields of embedded structs with inline tags,
+// which the engine styles do not have, so they are set here as fields of the struct.
+func overrideFields(v reflect.Value, values map[interface{}]interface{}, path string) error {
+
+	for key, value := range values {
+		name := fmt.Sprint(key)
+		fpath := path + "." + name
+		field, ok := styleField(v, strings.ToLower(name))
+		if !ok {
+			return fmt.Errorf("unknown style field:%s", fpath)
+		}
+		// Styles referenced by pointers, as the list styles, are modified in place
+		if m, ok := value.(map[interface{}]interface{}); ok {
+			target := field
+			if target.Kind() == reflect.Ptr && !target.IsNil() {
+				target = target.Elem()
+			}
+			if target.Kind() == reflect.Struct {
+				err := overrideFields(target, m, fpath)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if field.Kind() == reflect.Ptr {
+			return fmt.Errorf("unsupported style field:%s", fpath)
+		}
+		data, err := yaml.Marshal(value)
+		if err == nil {
+			err = yaml.Unmarshal(data, field.Addr().Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", fpath, err)
+		}
+	}
+	return nil
+}
+
+// styleField returns the exported field of the specified struct with the specified
+// lower case name, searching the fields of its embedded structs if not found
+func styleField(v reflect.Value, name string) (reflect.Value, bool) {
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && strings.ToLower(f.Name) == name {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.PkgPath == "" && f.Type.Kind() == reflect.Struct {
+			if field, ok := styleField(v.Field(i), name); ok {
+				return field, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setTheme sets the current theme.
+// The default style is updated in place so GUI elements which keep pointers to it
+// use the new style. The GUI is rebuilt and the current demo restarted in the next frame.
+func (app *App) setTheme(t *Theme) {
+
+	app.scaleStyle(t.Style)
+	*gui.StyleDefault() = *t.Style
+	app.theme = t
+	app.rebuildGui = true
+}
+
+// themeByName returns the built-in theme or the custom theme with the specified name
+func (app *App) themeByName(name string) (*Theme, error) {
+
+	if name == themeCustom {
+		return loadTheme(app.config.StyleFile)
+	}
+	return newTheme(name)
+}
+
+// selectTheme sets the theme with the specified name and saves it in the user configuration
+func (app *App) selectTheme(name string) {
+
+	t, err := app.themeByName(name)
+	if err != nil {
+		app.log.Error("Error setting theme:%v", err)
+		return
+	}
+	app.setTheme(t)
+	app.config.Theme = name
+	app.saveConfig()
+}
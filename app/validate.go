@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Command line option for validating a model file.
+// It is checked before the application window is created.
+var oValidate = flag.String("validate", "", "Validates the specified model file, prints the problems found and exits")
+
+// Severity levels of the validation issues
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue is a problem found by a model validator
+type ValidationIssue struct {
+	Severity string `json:"severity"` // SeverityError or SeverityWarning
+	Path     string `json:"path"`     // JSON path of the element with the problem
+	Message  string `json:"message"`  // Description of the problem
+}
+
+// ModelValidator validates the specified model file and returns the problems found.
+// Returns an error if the file could not be validated.
+type ModelValidator func(fpath string) ([]ValidationIssue, error)
+
+// modelValidator is the validator used by the -validate option
+var modelValidator ModelValidator
+
+// SetModelValidator sets the validator used by the -validate command line option.
+// It is set by the package which implements the model loaders.
+func SetModelValidator(v ModelValidator) {
+
+	modelValidator = v
+}
+
+// validationReport is the output of -validate in JSON format
+type validationReport struct {
+	File     string            `json:"file"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+}
+
+// runValidate checks for the -validate command line option and executes it,
+// exiting with status 1 if errors were found.
+// Returns false if the option was not specified.
+func runValidate() bool {
+
+	fpath, ok := lookupArg("validate", false)
+	if !ok {
+		return false
+	}
+	_, useJSON := lookupArg("json", true)
+	if fpath == "" {
+		fmt.Fprintf(os.Stderr, "%s: -validate requires a file\n", execName)
+		os.Exit(2)
+	}
+	if modelValidator == nil {
+		fmt.Fprintf(os.Stderr, "%s: no model validator available\n", execName)
+		os.Exit(1)
+	}
+	issues, err := modelValidator(fpath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", execName, err)
+		os.Exit(1)
+	}
+
+	report := validationReport{File: fpath, Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	if useJSON {
+		if report.Issues == nil {
+			report.Issues = []ValidationIssue{}
+		}
+		data, _ := json.MarshalIndent(&report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%-8s%s: %s\n", issue.Severity, issue.Path, issue.Message)
+		}
+		fmt.Printf("%s: %d error(s), %d warning(s)\n", fpath, report.Errors, report.Warnings)
+	}
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package app
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/camera/control"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// viewport is an area of the demo panel which shows the scene through its own camera
+type viewport struct {
+	name      string                // Viewport name shown at its upper left corner
+	panel     *gui.Panel            // Area of the viewport inside the demo panel
+	cam       camera.ICamera        // Viewport camera
+	ortho     *camera.Orthographic  // Orthographic camera or nil for the main viewport, which uses the application camera
+	aspect    float32               // Aspect ratio of the bounds of the orthographic camera
+	orbit     *control.OrbitControl // Orbit control or nil for the main viewport, which uses the application controls
+	dir       math32.Vector3        // Direction from the target to the orthographic camera
+	up        math32.Vector3        // Up vector of the orthographic camera
+	wireframe bool                  // Render the scene in wireframe
+	title     *gui.CheckBox         // Viewport name and wireframe checkbox
+}
+
+// wireframeState keeps the original wireframe state of a material
+type wireframeState struct {
+	mat  *material.Material
+	orig bool
+}
+
+// Viewport modes: number of viewports
+const (
+	viewSingle = 1
+	viewDual   = 2
+	viewQuad   = 4
+)
+
+// setViewMode sets the number of viewports which split the demo panel
+func (app *App) setViewMode(mode int) {
+
+	app.viewMode = mode
+	app.buildViewports()
+}
+
+// buildViewports removes the current viewports and creates the viewports for the current mode.
+// In the single mode the whole demo panel is used by the application camera.
+// Otherwise the first viewport uses the application camera and the others use
+// orthographic cameras with their own orbit controls for the top, front and side views,
+// which are created when the viewports are laid out.
+func (app *App) buildViewports() {
+
+	center := app.GuiPanel()
+	for _, vp := range app.viewports {
+		if vp.orbit != nil {
+			vp.orbit.Dispose()
+		}
+		center.Remove(vp.panel)
+		center.Remove(vp.title)
+		vp.panel.Dispose()
+		vp.title.Dispose()
+	}
+	app.viewports = nil
+	app.viewPlaced = false
+	if app.viewMode == viewSingle {
+		if h := center.ContentHeight(); h > 0 {
+			app.CameraPersp().SetAspect(center.ContentWidth() / h)
+		}
+		app.Renderer().SetGuiPanel3D(center)
+		app.Orbit().Enabled = app.camCtl == nil
+		return
+	}
+
+	names := []string{"Perspective", "Front", "Top", "Side"}
+	dirs := []math32.Vector3{{0, 0, 1}, {0, 0, 1}, {0, 1, 0}, {1, 0, 0}}
+	ups := []math32.Vector3{{0, 1, 0}, {0, 1, 0}, {0, 0, -1}, {0, 1, 0}}
+	for i := 0; i < app.viewMode; i++ {
+		vp := &viewport{name: names[i], dir: dirs[i], up: ups[i]}
+		// The viewport panel is not renderable and disabled so it does not
+		// receive the mouse events used by the camera controls
+		vp.panel = gui.NewPanel(0, 0)
+		vp.panel.SetRenderable(false)
+		vp.panel.SetEnabled(false)
+		center.Add(vp.panel)
+		vp.title = gui.NewCheckBox(vp.name + " (wireframe)")
+		vp.title.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			vp.wireframe = vp.title.Value()
+		})
+		center.Add(vp.title)
+		if i == 0 {
+			vp.cam = app.Camera()
+		}
+		app.viewports = append(app.viewports, vp)
+	}
+	app.layoutViewports()
+	app.Renderer().SetGuiPanel3D(app.viewports[0].panel)
+}
+
+// layoutViewports sets the positions and sizes of the viewports
+// and the aspect ratio of their cameras from the size of the demo panel.
+// The orthographic cameras are created again when the aspect ratio of
+// their viewports changes.
+func (app *App) layoutViewports() {
+
+	center := app.GuiPanel()
+	w := center.ContentWidth()
+	h := center.ContentHeight()
+	cols, rows := 2, 1
+	if len(app.viewports) == viewQuad {
+		rows = 2
+	}
+	vw := w / float32(cols)
+	vh := h / float32(rows)
+	aspect := float32(1)
+	if vh > 0 {
+		aspect = vw / vh
+	}
+	pad := app.Scaled(4)
+	for i, vp := range app.viewports {
+		x := float32(i%cols) * vw
+		y := float32(i/cols) * vh
+		vp.panel.SetPosition(x, y)
+		vp.panel.SetSize(vw, vh)
+		vp.title.SetPosition(x+pad, y+pad)
+		if i == 0 {
+			app.CameraPersp().SetAspect(aspect)
+		} else if vp.ortho == nil || vp.aspect != aspect {
+			app.newViewCamera(vp, aspect)
+		}
+	}
+}
+
+// newViewCamera creates the orthographic camera of a viewport with the specified
+// aspect ratio and its orbit control, which is enabled when the cursor is over the viewport.
+// The cameras are placed around the scene again before the next frame.
+func (app *App) newViewCamera(vp *viewport, aspect float32) {
+
+	enabled := false
+	if vp.orbit != nil {
+		enabled = vp.orbit.Enabled
+		vp.orbit.Dispose()
+	}
+	vp.ortho = camera.NewOrthographic(-aspect, aspect, 1, -1, 0.01, 1000)
+	vp.cam = vp.ortho
+	vp.aspect = aspect
+	vp.orbit = control.NewOrbitControl(vp.ortho, app.Window())
+	vp.orbit.EnableRotate = false
+	vp.orbit.Enabled = enabled
+	app.viewPlaced = false
+}
+
+// placeViewCameras places the orthographic cameras around the target of the
+// application camera at the same distance, sized to show the same area.
+func (app *App) placeViewCameras() {
+
+	cam := app.Camera().GetCamera()
+	pos := cam.Position()
+	target := cam.Target()
+	dist := pos.DistanceTo(&target)
+	if dist == 0 {
+		dist = 1
+	}
+	half := dist * math32.Tan(math32.DegToRad(30))
+	if p, ok := app.Camera().(*camera.Perspective); ok {
+		half = dist * math32.Tan(math32.DegToRad(p.Fov()/2))
+	}
+	for _, vp := range app.viewports[1:] {
+		c := vp.ortho.GetCamera()
+		p := vp.dir
+		p.MultiplyScalar(dist).Add(&target)
+		c.SetPositionVec(&p)
+		c.SetUp(&vp.up)
+		c.LookAt(&target)
+		// The camera bounds have half height 1, so the zoom sets the visible area
+		vp.ortho.SetZoom(1 / half)
+	}
+}
+
+// updateViewports is called before the application renders the frame.
+// It updates the viewports layout and the orbit control of the viewport
+// under the cursor and prepares the wireframe state of the main viewport.
+func (app *App) updateViewports() {
+
+	if len(app.viewports) == 0 {
+		return
+	}
+	app.viewports[0].cam = app.Camera()
+	app.layoutViewports()
+	if !app.viewPlaced {
+		app.placeViewCameras()
+		app.viewPlaced = true
+	}
+	if app.viewports[0].wireframe {
+		app.viewWireframe = setSceneWireframe(app.Scene())
+	}
+}
+
+// renderViewports is called after the application renders the frame with the main viewport.
+// It renders the scene in the other viewports and renders the GUI again over them
+// with an empty scene.
+func (app *App) renderViewports() {
+
+	restoreWireframe(app.viewWireframe)
+	app.viewWireframe = nil
+	if len(app.viewports) < 2 {
+		return
+	}
+	r := app.Renderer()
+	r.SetGui(nil)
+	for _, vp := range app.viewports[1:] {
+		var states []wireframeState
+		if vp.wireframe {
+			states = setSceneWireframe(app.Scene())
+		}
+		r.SetGuiPanel3D(vp.panel)
+		_, err := r.Render(vp.cam)
+		if err != nil {
+			app.log.Error("Error rendering viewport:%v", err)
+		}
+		restoreWireframe(states)
+	}
+
+	// Renders the GUI over the viewports
+	if app.viewEmpty == nil {
+		app.viewEmpty = core.NewNode()
+	}
+	r.SetGui(app.Gui())
+	r.SetScene(app.viewEmpty)
+	_, err := r.Render(app.Camera())
+	if err != nil {
+		app.log.Error("Error rendering viewport GUI:%v", err)
+	}
+	r.SetScene(app.Scene())
+	r.SetGuiPanel3D(app.viewports[0].panel)
+}
+
+// onViewportCursor enables only the orbit control of the viewport under the cursor.
+// The enabled control is not changed while a mouse button is pressed.
+func (app *App) onViewportCursor(evname string, ev interface{}) {
+
+	if len(app.viewports) == 0 {
+		return
+	}
+	switch evname {
+	case window.OnMouseDown:
+		app.viewDragging = true
+		return
+	case window.OnMouseUp:
+		app.viewDragging = false
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+	if app.viewDragging {
+		return
+	}
+	for i, vp := range app.viewports {
+		over := vp.panel.ContainsPosition(cev.Xpos, cev.Ypos)
+		if i == 0 {
+			app.Orbit().Enabled = over && app.camCtl == nil
+		} else {
+			vp.orbit.Enabled = over
+		}
+	}
+}
+
+// setSceneWireframe sets the wireframe state of all the materials of the scene
+// and returns their original states
+func setSceneWireframe(root core.INode) []wireframeState {
+
+	var states []wireframeState
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		if ig, ok := inode.(graphic.IGraphic); ok {
+			for _, gm := range ig.GetGraphic().Materials() {
+				mat := gm.GetMaterial().GetMaterial()
+				states = append(states, wireframeState{mat: mat, orig: mat.Wireframe()})
+				mat.SetWireframe(true)
+			}
+		}
+		for _, child := range inode.GetNode().Children() {
+			walk(child)
+		}
+	}
+	walk(root)
+	return states
+}
+
+// restoreWireframe restores the original wireframe state of the materials
+func restoreWireframe(states []wireframeState) {
+
+	for _, st := range states {
+		st.mat.SetWireframe(st.orig)
+	}
+}
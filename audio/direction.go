@@ -166,7 +166,7 @@ func NewPlayerCone(app *app.App, filename string, color *math32.Color) *PlayerCo
 	pc := new(PlayerCone)
 
 	// Creates audio source
-	player, err := audio.NewPlayer(app.DirData() + "/audio/" + filename)
+	player, err := app.AudioPlayer(app.DirData() + "/audio/" + filename)
 	if err != nil {
 		app.Log().Fatal("error:%s", err)
 	}
@@ -83,7 +83,7 @@ type PlayerControl struct {
 func NewPlayerControl(a *app.App, filename string) (*PlayerControl, error) {
 
 	// Creates player
-	player, err := audio.NewPlayer(a.DirData() + "/audio/" + filename)
+	player, err := a.AudioPlayer(a.DirData() + "/audio/" + filename)
 	if err != nil {
 		return nil, err
 	}
@@ -207,12 +207,6 @@ func NewPlayerControl(a *app.App, filename string) (*PlayerControl, error) {
 	return pc, nil
 }
 
-func (pc *PlayerControl) Dispose() {
-
-	pc.Panel.Dispose()
-	pc.player.Dispose()
-}
-
 func (pc *PlayerControl) UpdateTime() {
 
 	if pc.player.State() != al.Playing {
@@ -132,7 +132,7 @@ func NewPlayerSphere(a *app.App, filename string, color *math32.Color) *PlayerSp
 	ps := new(PlayerSphere)
 
 	// Creates audio source
-	player, err := audio.NewPlayer(a.DirData() + "/audio/" + filename)
+	player, err := a.AudioPlayer(a.DirData() + "/audio/" + filename)
 	if err != nil {
 		a.Log().Fatal("error:%s", err)
 	}
@@ -0,0 +1,371 @@
+package demos
+
+import (
+	"github.com/g3n/g3nd/app"
+)
+
+// Info maps the demo name string to its description, required assets and capabilities.
+// It is used by the -list and -info command line options.
+// Assets are relative to the data directory.
+var Info = map[string]*app.DemoInfo{
+	"animation.basic": {
+		Description:  "Keyframe animation of position, rotation and scale",
+		Capabilities: []string{"animation", "gui"},
+	},
+	"animation.crossfade": {
+		Description:  "Crossfade blending between animation clips with weights",
+		Capabilities: []string{"animation", "gui"},
+	},
+	"animation.morphtargets": {
+		Description:  "Animated morph target weights of a sphere",
+		Assets:       []string{"images/checkerboard.jpg"},
+		Capabilities: []string{"animation", "morph-targets", "texture"},
+	},
+	"audio.capture": {
+		Description:  "Captures audio from the default input device and shows the waveform",
+		Capabilities: []string{"audio", "audio-capture", "gui"},
+	},
+	"audio.direction": {
+		Description:  "Directional audio sources with sound cones",
+		Assets:       []string{"audio/Vivaldi1.wav", "audio/Bach1.ogg", "audio/engine.ogg", "audio/bomb2.ogg", "audio/tone_440hz.wav", "audio/tone_1khz.wav"},
+		Capabilities: []string{"audio", "gui"},
+	},
+	"audio.doppler": {
+		Description:  "Doppler effect of moving audio sources",
+		Assets:       []string{"audio/engine.ogg", "audio/tone_1khz.wav", "audio/tone_2khz.wav"},
+		Capabilities: []string{"audio", "gui"},
+	},
+	"audio.player": {
+		Description:  "Audio file players with play, pause, stop and gain controls",
+		Assets:       []string{"audio/bomb1.wav", "audio/Vivaldi1.wav", "audio/bomb2.ogg", "audio/Bach1.ogg"},
+		Capabilities: []string{"audio", "gui"},
+	},
+	"audio.position": {
+		Description:  "Positional audio sources around the listener",
+		Assets:       []string{"audio/Vivaldi1.wav", "audio/Bach1.ogg", "audio/bomb1.wav", "audio/bomb2.ogg", "audio/tone_440hz.wav", "audio/tone_1khz.wav"},
+		Capabilities: []string{"audio", "gui"},
+	},
+	"geometry.box": {
+		Description:  "Box geometry with adjustable segments",
+		Capabilities: []string{"gui"},
+	},
+	"geometry.circle": {
+		Description: "Circle geometries with different number of segments",
+	},
+	"geometry.cylinder": {
+		Description: "Cylinder and cone geometries",
+	},
+	"geometry.line_strip": {
+		Description: "Line strip graphic",
+	},
+	"geometry.lines": {
+		Description: "Line segments graphic",
+	},
+	"geometry.plane": {
+		Description: "Plane geometry",
+	},
+	"geometry.points": {
+		Description: "Points graphic",
+	},
+	"geometry.sphere": {
+		Description: "Sphere geometries with different number of segments",
+	},
+	"geometry.sprite": {
+		Description: "Sprites which always face the camera",
+	},
+	"geometry.torus": {
+		Description: "Torus geometry",
+	},
+	"gui.builder": {
+		Description:  "GUI panels built from YAML description files",
+		Assets:       []string{"gui", "images"},
+		Capabilities: []string{"gui", "file-select"},
+	},
+	"gui.button": {
+		Description:  "GUI buttons with icons, images and custom styles",
+		Assets:       []string{"images/ok.png"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.chart": {
+		Description:  "GUI chart with graphs and scales",
+		Capabilities: []string{"gui"},
+	},
+	"gui.checkradio": {
+		Description:  "GUI check boxes and radio buttons",
+		Capabilities: []string{"gui"},
+	},
+	"gui.custom_cursors": {
+		Description:  "Custom mouse cursors created from images",
+		Assets:       []string{"images/gauntlet_cursor.png", "images/gopher_cursor.png"},
+		Capabilities: []string{"gui", "cursors"},
+	},
+	"gui.dropdown": {
+		Description:  "GUI drop down lists",
+		Assets:       []string{"images/ok.png"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.edit": {
+		Description:  "GUI single and multiple line edit fields",
+		Capabilities: []string{"gui", "keyboard"},
+	},
+	"gui.folder": {
+		Description:  "GUI folder which shows and hides an enclosed panel",
+		Capabilities: []string{"gui"},
+	},
+	"gui.imagebutton": {
+		Description:  "GUI buttons with images for each state",
+		Assets:       []string{"images/blue_normal.png", "images/blue_over.png", "images/blue_pressed.png", "images/ok.png", "images/sprite0.png", "images/tiger1.jpg"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.imagelabel": {
+		Description:  "GUI labels with icons and images",
+		Assets:       []string{"icons/add2.png", "images/tiger1.jpg"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.itemscroller": {
+		Description:  "GUI item scrollers with dynamically added items",
+		Assets:       []string{"images/ok.png"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.label": {
+		Description:  "GUI labels with different fonts, sizes and colors",
+		Capabilities: []string{"gui"},
+	},
+	"gui.layout_dock": {
+		Description:  "GUI dock layout",
+		Capabilities: []string{"gui"},
+	},
+	"gui.layout_grid": {
+		Description:  "GUI grid layout",
+		Capabilities: []string{"gui"},
+	},
+	"gui.layout_hbox": {
+		Description:  "GUI horizontal box layout",
+		Capabilities: []string{"gui"},
+	},
+	"gui.layout_vbox": {
+		Description:  "GUI vertical box layout",
+		Capabilities: []string{"gui"},
+	},
+	"gui.list": {
+		Description:  "GUI vertical and horizontal lists",
+		Capabilities: []string{"gui"},
+	},
+	"gui.menu": {
+		Description:  "GUI menu bar and menus with shortcuts",
+		Capabilities: []string{"gui", "keyboard"},
+	},
+	"gui.panel": {
+		Description:  "GUI panels with borders, paddings and textures",
+		Assets:       []string{"images/tiger1.jpg"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.panel_children": {
+		Description:  "GUI panels moved with the keyboard inside their parents",
+		Capabilities: []string{"gui", "keyboard"},
+	},
+	"gui.panel_modal": {
+		Description:  "GUI modal panel",
+		Capabilities: []string{"gui"},
+	},
+	"gui.scrollbar": {
+		Description:  "GUI scroll bars",
+		Capabilities: []string{"gui"},
+	},
+	"gui.scroller": {
+		Description:  "GUI scrollers with interlocking scroll bars",
+		Assets:       []string{"images/uvgrid.jpg"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.slider": {
+		Description:  "GUI horizontal and vertical sliders",
+		Capabilities: []string{"gui"},
+	},
+	"gui.splitter": {
+		Description:  "GUI horizontal and vertical splitters",
+		Capabilities: []string{"gui"},
+	},
+	"gui.tabbar": {
+		Description:  "GUI tab bar with closable tabs",
+		Assets:       []string{"images/add.png", "images/call-start.png", "images/document-open.png", "images/document-revert.png"},
+		Capabilities: []string{"gui"},
+	},
+	"gui.table": {
+		Description:  "GUI table with sorting, resizable columns and context menu",
+		Capabilities: []string{"gui"},
+	},
+	"gui.tree": {
+		Description:  "GUI tree",
+		Capabilities: []string{"gui"},
+	},
+	"gui.window": {
+		Description:  "GUI windows",
+		Capabilities: []string{"gui"},
+	},
+	"helper.axis": {
+		Description: "Axis helper",
+	},
+	"light.point": {
+		Description:  "Moving point lights inside a box",
+		Capabilities: []string{"lights"},
+	},
+	"light.spot": {
+		Description:  "Moving spot light with adjustable parameters",
+		Capabilities: []string{"lights", "gui"},
+	},
+	"loader.collada": {
+		Description:  "Loads Collada models and animations",
+		Assets:       []string{"collada/scene.dae", "images"},
+		Capabilities: []string{"model-loader", "animation", "file-select"},
+	},
+	"loader.gltf": {
+		Description:  "Loads glTF models and animations",
+		Assets:       []string{"gltf/CesiumMan/glTF/CesiumMan.gltf"},
+		Capabilities: []string{"model-loader", "animation", "file-select"},
+	},
+	"loader.obj": {
+		Description:  "Loads Wavefront OBJ models with MTL materials",
+		Assets:       []string{"obj/cubemultitex.obj", "obj/cubemultitex.mtl"},
+		Capabilities: []string{"model-loader", "file-select"},
+	},
+	"loader.stl_ply": {
+		Description:  "Loads STL and PLY meshes with vertex colors and PLY point clouds",
+		Assets:       []string{"stl", "ply"},
+		Capabilities: []string{"model-loader", "file-select"},
+	},
+	"loader.viewer": {
+		Description:  "Loads models of all registered formats selected by file extension",
+		Assets:       []string{"gltf/DamagedHelmet/glTF/DamagedHelmet.gltf", "images"},
+		Capabilities: []string{"model-loader", "animation", "file-select"},
+	},
+	"material.blending": {
+		Description:  "Material blending modes",
+		Assets:       []string{"images/uvgrid.jpg", "images/sprite0.jpg", "images/sprite0.png", "images/lensflare0.png", "images/lensflare0_alpha.png"},
+		Capabilities: []string{"transparency", "texture"},
+	},
+	"material.boxmulti": {
+		Description: "Box with a different material for each face",
+	},
+	"material.boxmulti2": {
+		Description:  "Box with a different texture for each face",
+		Assets:       []string{"images/brick1.jpg", "images/checkerboard.jpg", "images/moss.png", "images/tiger1.jpg", "images/uvgrid.jpg", "images/wall1.jpg"},
+		Capabilities: []string{"texture"},
+	},
+	"material.physical_helmet": {
+		Description:  "Physically based rendering of the damaged helmet model",
+		Assets:       []string{"obj/DamagedHelmet.obj", "obj/DamagedHelmet_AO.jpg", "obj/DamagedHelmet_albedo.jpg", "obj/DamagedHelmet_emissive.jpg", "obj/DamagedHelmet_metalRoughness.jpg", "obj/DamagedHelmet_normal.jpg"},
+		Capabilities: []string{"pbr", "model-loader", "texture"},
+	},
+	"material.physical_variations": {
+		Description:  "Physically based materials with varying metalness and roughness",
+		Capabilities: []string{"pbr", "lights", "gui"},
+	},
+	"other.children": {
+		Description: "Hierarchy of rotating child nodes",
+	},
+	"other.morphtargets": {
+		Description:  "Morph target weights controlled by sliders",
+		Assets:       []string{"images/checkerboard.jpg"},
+		Capabilities: []string{"morph-targets", "texture", "gui"},
+	},
+	"other.performance": {
+		Description: "Performance test with thousands of meshes",
+	},
+	"other.pitch": {
+		Description:  "Model orientation controlled with the keyboard",
+		Capabilities: []string{"keyboard"},
+	},
+	"other.points": {
+		Description:  "Points rendered with textured sprites",
+		Assets:       []string{"images/snowflake1.png", "images/snowflake2.png", "images/snowflake3.png", "images/snowflake4.png", "images/snowflake5.png"},
+		Capabilities: []string{"texture", "transparency"},
+	},
+	"other.raycast": {
+		Description:  "Object picking with the mouse using a raycaster",
+		Capabilities: []string{"raycast", "mouse"},
+	},
+	"other.skybox": {
+		Description:  "Skybox with cube map textures",
+		Assets:       []string{"images/sanfrancisco"},
+		Capabilities: []string{"skybox", "texture"},
+	},
+	"other.sprite_anim": {
+		Description:  "Sprite animations from texture atlases",
+		Assets:       []string{"images/explosion4.png", "images/explosion7.png", "images/smoke30.png", "images/walksequence.png"},
+		Capabilities: []string{"texture", "animation"},
+	},
+	"other.tank": {
+		Description:  "Tank model driven with the keyboard",
+		Assets:       []string{"images/wheel.png"},
+		Capabilities: []string{"keyboard", "texture"},
+	},
+	"other.text": {
+		Description:  "Text rendered to textures with a TrueType font",
+		Assets:       []string{"fonts/FreeSans.ttf"},
+		Capabilities: []string{"text", "texture"},
+	},
+	"physics-experimental.basic": {
+		Description:  "Experimental physics with a few rigid bodies",
+		Capabilities: []string{"physics", "keyboard"},
+	},
+	"physics-experimental.sphere_box": {
+		Description:  "Experimental physics with sphere and box collisions",
+		Capabilities: []string{"physics", "keyboard"},
+	},
+	"physics-experimental.spheres": {
+		Description:  "Experimental physics with many colliding spheres",
+		Assets:       []string{"images/ground2.jpg", "images/smoke30.png", "images/uvgrid.jpg"},
+		Capabilities: []string{"physics", "keyboard", "texture"},
+	},
+	"physics-experimental.spheres2": {
+		Description:  "Experimental physics with spheres falling on the ground",
+		Assets:       []string{"images/ground2.jpg", "images/uvgrid.jpg"},
+		Capabilities: []string{"physics", "keyboard", "texture"},
+	},
+	"scene.file": {
+		Description:  "Scenes described in YAML or JSON files with hot reload",
+		Assets:       []string{"scene/shapes.yaml"},
+		Capabilities: []string{"scene-file", "model-loader", "file-select"},
+	},
+	"shader.bricks": {
+		Description:  "Custom shader which procedurally generates bricks",
+		Capabilities: []string{"custom-shader"},
+	},
+	"shader.earth": {
+		Description:  "Earth with day, night and specular textures in a custom shader",
+		Assets:       []string{"images/earth_clouds_big.jpg", "images/earth_spec_big.jpg", "images/earth_night_big.jpg", "images/lensflare0_alpha.png", "images/space"},
+		Capabilities: []string{"custom-shader", "skybox", "texture"},
+	},
+	"shader.geometry": {
+		Description:  "Wireframe, vertex and face normals generated by a geometry shader",
+		Capabilities: []string{"custom-shader", "geometry-shader", "gui"},
+	},
+	"texture.box": {
+		Description:  "Textured boxes",
+		Assets:       []string{"images/brick1.jpg", "images/checkerboard.jpg", "images/moss.png", "images/wall1.jpg"},
+		Capabilities: []string{"texture"},
+	},
+	"texture.circle": {
+		Description:  "Textured circle",
+		Assets:       []string{"images/tiger1.jpg"},
+		Capabilities: []string{"texture"},
+	},
+	"texture.cylinder": {
+		Description:  "Textured cylinders",
+		Assets:       []string{"images/brick1.jpg", "images/checkerboard.jpg", "images/moss.png"},
+		Capabilities: []string{"texture"},
+	},
+	"texture.plane": {
+		Description:  "Textured planes",
+		Assets:       []string{"images/tiger1.jpg", "images/uvgrid.jpg"},
+		Capabilities: []string{"texture"},
+	},
+	"texture.sphere": {
+		Description:  "Textured spheres",
+		Assets:       []string{"images/brick1.jpg", "images/checkerboard.jpg", "images/earth_clouds_big.jpg", "images/uvgrid.jpg"},
+		Capabilities: []string{"texture"},
+	},
+	"|tests|.model": {
+		Description: "Model for new tests",
+	},
+}
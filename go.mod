@@ -5,12 +5,12 @@ go 1.12
 require (
 	github.com/g3n/engine v0.0.0-20190314190124-70eaa04e801c
 	github.com/g3n/g3nd v0.0.0-20180818131439-2efa959077e7
-	github.com/go-gl/glfw v0.0.0-20190217072633-93b30450e032 // indirect
+	github.com/go-gl/glfw v0.0.0-20190217072633-93b30450e032
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/kardianos/osext v0.0.0-20190222173326-2bc1f35cddc0
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/kr/pty v1.1.3 // indirect
 	golang.org/x/image v0.0.0-20190227222117-0694c2d4d067 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
@@ -0,0 +1,175 @@
+package loader
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/g3nd/util"
+)
+
+// Maximum playback speed of the clip player speed sliders
+const clipMaxSpeed = 4
+
+// clipPlayer is a panel with playback controls for each animation of a loaded model.
+// Any number of clips can play at the same time.
+type clipPlayer struct {
+	*gui.Panel
+	clips []*clipState
+}
+
+// clipState is the playback state and controls of an animation clip
+type clipState struct {
+	anim     util.ModelAnimation // Animation
+	name     string              // Clip name
+	duration float32             // Duration in seconds or zero if unknown
+	playing  bool                // Clip is playing
+	loop     bool                // Restart the clip at its end
+	speed    float32             // Playback speed factor
+	time     float32             // Current clip time in seconds
+	label    *gui.Label          // Label with the clip name and time
+	scrub    *gui.Slider         // Time scrub bar
+	updating bool                // Scrub bar is being updated from the clip time
+}
+
+// newClipPlayer creates and returns the player panel for the specified animations.
+// All clips start playing in loop.
+func newClipPlayer(anims []util.ModelAnimation) *clipPlayer {
+
+	cp := new(clipPlayer)
+	cp.Panel = gui.NewPanel(util.Scaled(300), 0)
+	cp.SetPaddings(2, 2, 2, 2)
+	layout := gui.NewVBoxLayout()
+	layout.SetSpacing(util.Scaled(4))
+	layout.SetAutoHeight(true)
+	cp.SetLayout(layout)
+
+	for i, anim := range anims {
+		cs := &clipState{anim: anim, playing: true, loop: true, speed: 1}
+		cs.name = fmt.Sprintf("animation %d", i)
+		// The loaders wrap their animations with their names and durations
+		if n, ok := anim.(NamedAnimation); ok {
+			cs.name = n.Name()
+		}
+		if d, ok := anim.(TimedAnimation); ok {
+			cs.duration = d.Duration()
+		}
+		cp.clips = append(cp.clips, cs)
+		cp.addClipControls(cs)
+	}
+	return cp
+}
+
+// addClipControls adds the controls of the specified clip to the panel
+func (cp *clipPlayer) addClipControls(cs *clipState) {
+
+	rowHeight := util.Scaled(24)
+	cs.label = gui.NewLabel(cs.name)
+	cp.Add(cs.label)
+
+	// Playback buttons and loop checkbox
+	row := gui.NewPanel(cp.ContentWidth(), rowHeight)
+	hbox := gui.NewHBoxLayout()
+	hbox.SetSpacing(util.Scaled(4))
+	row.SetLayout(hbox)
+	bPlay := gui.NewButton("Play")
+	bPlay.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		// Restarts a clip which stopped at its end
+		if cs.duration > 0 && cs.time >= cs.duration {
+			cs.seek(0)
+		}
+		cs.playing = true
+	})
+	row.Add(bPlay)
+	bPause := gui.NewButton("Pause")
+	bPause.Subscribe(gui.OnClick, func(evname string, ev interface{}) { cs.playing = false })
+	row.Add(bPause)
+	bStop := gui.NewButton("Stop")
+	bStop.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		cs.playing = false
+		cs.seek(0)
+	})
+	row.Add(bStop)
+	cbLoop := gui.NewCheckBox("Loop")
+	cbLoop.SetValue(cs.loop)
+	cbLoop.Subscribe(gui.OnChange, func(evname string, ev interface{}) { cs.loop = cbLoop.Value() })
+	row.Add(cbLoop)
+	cp.Add(row)
+
+	// Speed slider
+	sSpeed := gui.NewHSlider(cp.ContentWidth(), rowHeight)
+	sSpeed.SetValue(cs.speed / clipMaxSpeed)
+	sSpeed.SetText(fmt.Sprintf("speed %.2f", cs.speed))
+	sSpeed.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		cs.speed = sSpeed.Value() * clipMaxSpeed
+		sSpeed.SetText(fmt.Sprintf("speed %.2f", cs.speed))
+	})
+	cp.Add(sSpeed)
+
+	// Time scrub bar, only if the clip duration is known
+	if cs.duration <= 0 {
+		return
+	}
+	cs.scrub = gui.NewHSlider(cp.ContentWidth(), rowHeight)
+	cs.scrub.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		if !cs.updating {
+			cs.seek(cs.scrub.Value() * cs.duration)
+		}
+	})
+	cp.Add(cs.scrub)
+}
+
+// update advances the playing clips by the specified time in seconds
+func (cp *clipPlayer) update(dt float32) {
+
+	for _, cs := range cp.clips {
+		if cs.playing {
+			cs.advance(dt * cs.speed)
+		}
+		cs.updateControls()
+	}
+}
+
+// advance advances the clip time by the specified delta,
+// restarting the clip or stopping it at its end
+func (cs *clipState) advance(delta float32) {
+
+	if cs.duration <= 0 {
+		cs.time += delta
+		cs.anim.Update(delta)
+		return
+	}
+	t := cs.time + delta
+	if t < cs.duration {
+		cs.time = t
+		cs.anim.Update(delta)
+		return
+	}
+	if cs.loop {
+		cs.seek(float32(math.Mod(float64(t), float64(cs.duration))))
+		return
+	}
+	cs.seek(cs.duration)
+	cs.playing = false
+}
+
+// seek sets the clip time by restarting the animation and advancing it to the specified time
+func (cs *clipState) seek(t float32) {
+
+	cs.time = t
+	cs.anim.Reset()
+	cs.anim.Update(t)
+}
+
+// updateControls updates the clip label and scrub bar from the clip time
+func (cs *clipState) updateControls() {
+
+	if cs.duration <= 0 {
+		cs.label.SetText(fmt.Sprintf("%s  %.2fs", cs.name, cs.time))
+		return
+	}
+	cs.label.SetText(fmt.Sprintf("%s  %.2f / %.2fs", cs.name, cs.time, cs.duration))
+	cs.updating = true
+	cs.scrub.SetValue(cs.time / cs.duration)
+	cs.updating = false
+}
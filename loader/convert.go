@@ -0,0 +1,414 @@
+package loader
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/util"
+)
+
+func init() {
+	app.RegisterCommand("convert", "convert [-texpath dirs] <input> <output.gltf|output.glb|output.obj>", cmdConvert)
+	util.RegisterModelWriter(writeModel, ".gltf", ".glb", ".obj")
+}
+
+// TextureSources is the optional interface of loaders which know the image files
+// of the textures of their loaded file
+type TextureSources interface {
+	TextureSource(tex *texture.Texture2D) string // Path of the image file of the texture or empty if unknown
+}
+
+// exportDoc is a loaded model converted to a format independent description for the writers
+type exportDoc struct {
+	nodes      []*exportNode
+	roots      []int // Indices of the root nodes
+	meshes     []*exportMesh
+	materials  []*exportMaterial
+	animations []*exportAnimation
+	nodeIndex  map[core.INode]int // Indices of the nodes by their model nodes
+	uvTopDown  bool               // Texture coordinates have the origin at the top left corner of the images as in glTF
+	warnings   []string           // Problems found during the conversion
+}
+
+// exportNode is a node of the model hierarchy
+type exportNode struct {
+	name     string
+	pos      math32.Vector3
+	quat     math32.Quaternion
+	scale    math32.Vector3
+	world    math32.Matrix4 // World transform
+	children []int          // Indices of the child nodes
+	mesh     int            // Index of the mesh or -1
+}
+
+// exportMesh contains the vertex attributes of a mesh and its primitives
+type exportMesh struct {
+	name      string
+	positions []float32
+	normals   []float32 // Vertex normals or nil
+	uvs       []float32 // Vertex texture coordinates or nil
+	prims     []*exportPrimitive
+}
+
+// exportPrimitive is a list of triangles of a mesh with the same material
+type exportPrimitive struct {
+	indices  []uint32
+	material int // Index of the material
+}
+
+// exportMaterial is a material reduced to its color and color texture
+type exportMaterial struct {
+	name    string
+	color   math32.Color4
+	texture string // Path of the color texture image or empty
+}
+
+// exportAnimation is a glTF animation with its channels targeting the exported nodes
+type exportAnimation struct {
+	name     string
+	samplers []*exportSampler
+	channels []exportChannel
+}
+
+// exportSampler contains the keyframes of an animation sampler
+type exportSampler struct {
+	times         []float32 // Keyframe times in seconds
+	values        []float32 // Keyframe values
+	typ           string    // glTF accessor type of the values
+	interpolation string    // glTF interpolation or empty for linear
+}
+
+// exportChannel is an animated property of a node
+type exportChannel struct {
+	sampler int    // Index of the sampler
+	node    int    // Index of the node
+	path    string // Animated property: translation, rotation or scale
+}
+
+// cmdConvert loads a model file with the registered loaders
+// and writes it in the format of the output file extension
+func cmdConvert(args []string, demoMap map[string]app.IDemo) error {
+
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	texPath := fs.String("texpath", "", "Additional directories to search for textures, separated by the OS path list separator")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: g3nd convert [-texpath dirs] <input> <output.gltf|output.glb|output.obj>")
+	}
+	input, output := fs.Arg(0), fs.Arg(1)
+	util.SetTexturePaths(filepath.SplitList(*texPath)...)
+
+	// Checks the output format before loading
+	ext := strings.ToLower(filepath.Ext(output))
+	if ext != ".gltf" && ext != ".glb" && ext != ".obj" {
+		return fmt.Errorf("unsupported output format:%s", ext)
+	}
+
+	l, err := util.FindModelLoader(input)
+	if err != nil {
+		return err
+	}
+	model, anims, err := l.Load(input)
+	if err != nil {
+		return err
+	}
+	sources, _ := l.(TextureSources)
+	doc := newExportDoc(model, sources)
+	_, doc.uvTopDown = l.(*gltfLoader)
+	if d, ok := l.(Diagnostics); ok {
+		doc.warnings = append(doc.warnings, d.Warnings()...)
+	}
+	// The keyframes of glTF animations are copied from the document, as
+	// the engine animations created by the loaders do not expose them
+	if gl, ok := l.(*gltfLoader); ok && ext != ".obj" {
+		doc.addGLTFAnimations(gl.doc)
+	} else if len(anims) > 0 {
+		doc.warnings = append(doc.warnings, fmt.Sprintf("%d animation(s) not converted: only the animations of glTF files are converted, to glTF files", len(anims)))
+	}
+
+	err = doc.write(output)
+	for _, w := range doc.warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	return err
+}
+
+// writeModel satisfies the util.ModelWriter function type, converting a node hierarchy
+// such as the scene of a demo. The image files of its textures are unknown.
+func writeModel(root core.INode, fpath string) ([]string, error) {
+
+	doc := newExportDoc(root, nil)
+	err := doc.write(fpath)
+	return doc.warnings, err
+}
+
+// write writes the converted model in the format of the extension of the specified file
+func (doc *exportDoc) write(fpath string) error {
+
+	switch ext := strings.ToLower(filepath.Ext(fpath)); ext {
+	case ".gltf":
+		return writeGLTF(doc, fpath, false)
+	case ".glb":
+		return writeGLTF(doc, fpath, true)
+	case ".obj":
+		return writeOBJ(doc, fpath)
+	default:
+		return fmt.Errorf("unsupported output format:%s", ext)
+	}
+}
+
+// newExportDoc converts the specified model hierarchy using the specified
+// texture sources, which may be nil, to find the texture image files
+func newExportDoc(model core.INode, sources TextureSources) *exportDoc {
+
+	doc := &exportDoc{nodeIndex: make(map[core.INode]int)}
+	model.GetNode().UpdateMatrixWorld()
+	mats := make(map[*material.Material]int)
+	texWarned := false
+	skinned := 0
+	var addNode func(inode core.INode) int
+	addNode = func(inode core.INode) int {
+		node := inode.GetNode()
+		en := &exportNode{name: node.Name(), pos: node.Position(), quat: node.Quaternion(), scale: node.Scale(), mesh: -1}
+		en.world = node.MatrixWorld()
+		idx := len(doc.nodes)
+		doc.nodes = append(doc.nodes, en)
+		doc.nodeIndex[inode] = idx
+		switch g := inode.(type) {
+		case *graphic.Mesh:
+			en.mesh = doc.addMesh(g, mats, sources, &texWarned)
+		case *graphic.RiggedMesh:
+			// Skinned meshes are converted with the vertices of their bind pose
+			en.mesh = doc.addMesh(g.Mesh, mats, sources, &texWarned)
+			skinned++
+		case graphic.IGraphic:
+			doc.warnings = append(doc.warnings, fmt.Sprintf("node %q: only meshes are converted", node.Name()))
+		}
+		for _, child := range node.Children() {
+			en.children = append(en.children, addNode(child))
+		}
+		return idx
+	}
+	doc.roots = append(doc.roots, addNode(model))
+	if skinned > 0 {
+		doc.warnings = append(doc.warnings, fmt.Sprintf("%d skinned mesh(es) converted without their skins: joints and weights are not converted", skinned))
+	}
+	return doc
+}
+
+// addGLTFAnimations adds the animations of the specified glTF document, whose
+// default scene is the converted model, with their channels targeting the exported nodes.
+// Channels which cannot be converted are skipped with a warning.
+func (doc *exportDoc) addGLTFAnimations(g *gltf.GLTF) {
+
+	for i, anim := range g.Animations {
+		name := anim.Name
+		if name == "" {
+			name = fmt.Sprintf("animation %d", i)
+		}
+		ea := &exportAnimation{name: name}
+		samplers := make(map[int]int) // Indices of the exported samplers by their document indices
+		for j, ch := range anim.Channels {
+			if ch.Target.Node == nil {
+				continue
+			}
+			// The morph targets are not converted, so their weights are not animated
+			if ch.Target.Path == "weights" {
+				doc.warnings = append(doc.warnings, fmt.Sprintf("%s: channel %d not converted: morph targets are not converted", name, j))
+				continue
+			}
+			// The document caches the nodes of the loaded scene
+			inode, err := g.LoadNode(*ch.Target.Node)
+			node, ok := doc.nodeIndex[inode]
+			if err != nil || !ok {
+				doc.warnings = append(doc.warnings, fmt.Sprintf("%s: channel %d not converted: node %d is not in the converted scene", name, j, *ch.Target.Node))
+				continue
+			}
+			sidx, ok := samplers[ch.Sampler]
+			if !ok {
+				sampler := anim.Samplers[ch.Sampler]
+				es := &exportSampler{interpolation: sampler.Interpolation}
+				es.times, _, err = gltfAccessorFloats(g, sampler.Input)
+				if err == nil {
+					es.values, es.typ, err = gltfAccessorFloats(g, sampler.Output)
+				}
+				if err != nil {
+					doc.warnings = append(doc.warnings, fmt.Sprintf("%s: channel %d not converted: %v", name, j, err))
+					continue
+				}
+				sidx = len(ea.samplers)
+				samplers[ch.Sampler] = sidx
+				ea.samplers = append(ea.samplers, es)
+			}
+			ea.channels = append(ea.channels, exportChannel{sampler: sidx, node: node, path: ch.Target.Path})
+		}
+		if len(ea.channels) > 0 {
+			doc.animations = append(doc.animations, ea)
+		}
+	}
+}
+
+// gltfAccessorFloats returns the values and the type of the specified accessor of a glTF document.
+// Only accessors of float components are supported, which are used by most animations.
+func gltfAccessorFloats(g *gltf.GLTF, idx int) ([]float32, string, error) {
+
+	acc := g.Accessors[idx]
+	if acc.ComponentType != gltfFloat || acc.BufferView == nil || acc.Sparse != nil {
+		return nil, "", fmt.Errorf("accessor %d: only dense float accessors are converted", idx)
+	}
+	data, err := g.LoadBufferView(*acc.BufferView)
+	if err != nil {
+		return nil, "", err
+	}
+	ncomp := gltfTypeComponents[acc.Type]
+	stride := ncomp * 4
+	if bv := g.BufferViews[*acc.BufferView]; bv.ByteStride != nil && *bv.ByteStride > 0 {
+		stride = *bv.ByteStride
+	}
+	values := make([]float32, 0, acc.Count*ncomp)
+	for i := 0; i < acc.Count; i++ {
+		offset := acc.ByteOffset + i*stride
+		for c := 0; c < ncomp; c++ {
+			values = append(values, math.Float32frombits(binary.LittleEndian.Uint32(data[offset+c*4:])))
+		}
+	}
+	return values, acc.Type, nil
+}
+
+// addMesh adds the specified mesh and its new materials and returns its index
+func (doc *exportDoc) addMesh(mesh *graphic.Mesh, mats map[*material.Material]int, sources TextureSources, texWarned *bool) int {
+
+	geom := mesh.GetGeometry()
+	em := &exportMesh{name: mesh.Name()}
+	em.positions = readAttrib(geom, gls.VertexPosition, 3)
+	em.normals = readAttrib(geom, gls.VertexNormal, 3)
+	em.uvs = readAttrib(geom, gls.VertexTexcoord, 2)
+	indices := []uint32(geom.Indices())
+	if len(indices) == 0 {
+		for i := 0; i < len(em.positions)/3; i++ {
+			indices = append(indices, uint32(i))
+		}
+	}
+
+	// Materials of the mesh
+	var matIndices []int
+	for _, gm := range mesh.Materials() {
+		mat := gm.GetMaterial().GetMaterial()
+		idx, ok := mats[mat]
+		if !ok {
+			idx = len(doc.materials)
+			mats[mat] = idx
+			doc.materials = append(doc.materials, newExportMaterial(gm.GetMaterial(), idx, sources, doc, texWarned))
+		}
+		matIndices = append(matIndices, idx)
+	}
+	if len(matIndices) == 0 {
+		doc.warnings = append(doc.warnings, fmt.Sprintf("mesh %q has no material", mesh.Name()))
+		return -1
+	}
+
+	// One primitive for each geometry group with its own material
+	if len(matIndices) > 1 && geom.GroupCount() > 0 {
+		for i := 0; i < geom.GroupCount() && i < len(matIndices); i++ {
+			group := geom.GroupAt(i)
+			end := group.Start + group.Count
+			if end > len(indices) {
+				end = len(indices)
+			}
+			em.prims = append(em.prims, &exportPrimitive{indices: indices[group.Start:end], material: matIndices[i]})
+		}
+	} else {
+		em.prims = append(em.prims, &exportPrimitive{indices: indices, material: matIndices[0]})
+	}
+	doc.meshes = append(doc.meshes, em)
+	return len(doc.meshes) - 1
+}
+
+// newExportMaterial returns the color and color texture of the specified material
+func newExportMaterial(imat material.IMaterial, idx int, sources TextureSources, doc *exportDoc, texWarned *bool) *exportMaterial {
+
+	em := &exportMaterial{name: fmt.Sprintf("material%d", idx), color: math32.Color4{1, 1, 1, 1}}
+	// The standard and phong materials are converted to their diffuse color and opacity.
+	// The engine physical materials do not return their base color, so they keep the
+	// default opaque white color.
+	var std *material.Standard
+	switch m := imat.(type) {
+	case *material.Standard:
+		std = m
+	case *material.Phong:
+		std = &m.Standard
+	}
+	if std != nil {
+		c := std.DiffuseColor()
+		em.color = math32.Color4{c.R, c.G, c.B, std.Opacity()}
+	}
+
+	textures := imat.GetMaterial().Textures()
+	if len(textures) == 0 {
+		return em
+	}
+	if sources != nil {
+		em.texture = sources.TextureSource(textures[0])
+	}
+	if em.texture == "" && !*texWarned {
+		*texWarned = true
+		doc.warnings = append(doc.warnings, "the image files of some textures are unknown and their references are not converted")
+	}
+	return em
+}
+
+// readAttrib returns the values of the specified vertex attribute with the specified
+// number of components from the geometry, or nil if the geometry does not have it
+func readAttrib(geom *geometry.Geometry, attr gls.AttribType, size int) []float32 {
+
+	vbo := geom.VBO(attr)
+	if vbo == nil {
+		return nil
+	}
+	buf := *vbo.Buffer()
+	stride := vbo.StrideSize() / 4
+	if stride == 0 {
+		stride = size
+	}
+	var values []float32
+	for i := vbo.AttribOffset(attr) / 4; i+size <= len(buf); i += stride {
+		values = append(values, buf[i:i+size]...)
+	}
+	return values
+}
+
+// relativeURI returns the path of the specified file relative to the specified directory
+// with forward slashes, as used in the model files
+func relativeURI(dir, fpath string) string {
+
+	abs, err := filepath.Abs(fpath)
+	if err != nil {
+		return filepath.ToSlash(fpath)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(fpath)
+	}
+	rel, err := filepath.Rel(absDir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
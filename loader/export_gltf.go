@@ -0,0 +1,376 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// glTF constants used by the writer
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+	gltfTriangles    = 4
+	glbMagic         = 0x46546C67 // "glTF"
+	glbChunkJSON     = 0x4E4F534A // "JSON"
+	glbChunkBIN      = 0x004E4942 // "BIN"
+)
+
+// Minimal glTF 2.0 document written by the converter
+type (
+	gltfOutDoc struct {
+		Asset       gltfOutAsset        `json:"asset"`
+		Scene       int                 `json:"scene"`
+		Scenes      []gltfOutScene      `json:"scenes"`
+		Nodes       []gltfOutNode       `json:"nodes,omitempty"`
+		Meshes      []gltfOutMesh       `json:"meshes,omitempty"`
+		Materials   []gltfOutMaterial   `json:"materials,omitempty"`
+		Textures    []gltfOutTexture    `json:"textures,omitempty"`
+		Images      []gltfOutImage      `json:"images,omitempty"`
+		Accessors   []gltfOutAccessor   `json:"accessors,omitempty"`
+		BufferViews []gltfOutBufferView `json:"bufferViews,omitempty"`
+		Buffers     []gltfOutBuffer     `json:"buffers,omitempty"`
+		Animations  []gltfOutAnimation  `json:"animations,omitempty"`
+	}
+	gltfOutAsset struct {
+		Version   string `json:"version"`
+		Generator string `json:"generator"`
+	}
+	gltfOutScene struct {
+		Nodes []int `json:"nodes"`
+	}
+	gltfOutNode struct {
+		Name        string      `json:"name,omitempty"`
+		Children    []int       `json:"children,omitempty"`
+		Mesh        *int        `json:"mesh,omitempty"`
+		Translation *[3]float32 `json:"translation,omitempty"`
+		Rotation    *[4]float32 `json:"rotation,omitempty"`
+		Scale       *[3]float32 `json:"scale,omitempty"`
+	}
+	gltfOutMesh struct {
+		Name       string             `json:"name,omitempty"`
+		Primitives []gltfOutPrimitive `json:"primitives"`
+	}
+	gltfOutPrimitive struct {
+		Attributes map[string]int `json:"attributes"`
+		Indices    int            `json:"indices"`
+		Material   int            `json:"material"`
+		Mode       int            `json:"mode"`
+	}
+	gltfOutMaterial struct {
+		Name                 string     `json:"name,omitempty"`
+		PbrMetallicRoughness gltfOutPbr `json:"pbrMetallicRoughness"`
+		AlphaMode            string     `json:"alphaMode,omitempty"`
+	}
+	gltfOutPbr struct {
+		BaseColorFactor  [4]float32         `json:"baseColorFactor"`
+		BaseColorTexture *gltfOutTextureRef `json:"baseColorTexture,omitempty"`
+		MetallicFactor   float32            `json:"metallicFactor"`
+		RoughnessFactor  float32            `json:"roughnessFactor"`
+	}
+	gltfOutTextureRef struct {
+		Index int `json:"index"`
+	}
+	gltfOutTexture struct {
+		Source int `json:"source"`
+	}
+	gltfOutImage struct {
+		URI        string `json:"uri,omitempty"`
+		BufferView *int   `json:"bufferView,omitempty"`
+		MimeType   string `json:"mimeType,omitempty"`
+	}
+	gltfOutAccessor struct {
+		BufferView    int       `json:"bufferView"`
+		ComponentType int       `json:"componentType"`
+		Count         int       `json:"count"`
+		Type          string    `json:"type"`
+		Min           []float32 `json:"min,omitempty"`
+		Max           []float32 `json:"max,omitempty"`
+	}
+	gltfOutBufferView struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		Target     int `json:"target,omitempty"`
+	}
+	gltfOutBuffer struct {
+		URI        string `json:"uri,omitempty"`
+		ByteLength int    `json:"byteLength"`
+	}
+	gltfOutAnimation struct {
+		Name     string                    `json:"name,omitempty"`
+		Channels []gltfOutChannel          `json:"channels"`
+		Samplers []gltfOutAnimationSampler `json:"samplers"`
+	}
+	gltfOutChannel struct {
+		Sampler int           `json:"sampler"`
+		Target  gltfOutTarget `json:"target"`
+	}
+	gltfOutTarget struct {
+		Node int    `json:"node"`
+		Path string `json:"path"`
+	}
+	gltfOutAnimationSampler struct {
+		Input         int    `json:"input"`
+		Output        int    `json:"output"`
+		Interpolation string `json:"interpolation,omitempty"`
+	}
+)
+
+// Media types of the images embedded in GLB files by extension
+var glbMimeTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+}
+
+// gltfWriter builds a glTF document and its binary buffer
+type gltfWriter struct {
+	doc gltfOutDoc
+	bin bytes.Buffer
+}
+
+// writeGLTF writes the specified model as a glTF file with an external binary
+// buffer file or, if glb is true, as a GLB file with the texture images embedded
+func writeGLTF(doc *exportDoc, fpath string, glb bool) error {
+
+	w := new(gltfWriter)
+	w.doc.Asset = gltfOutAsset{Version: "2.0", Generator: "g3nd convert"}
+	w.doc.Scenes = []gltfOutScene{{Nodes: doc.roots}}
+	dir := filepath.Dir(fpath)
+
+	// Materials and textures
+	images := make(map[string]int)
+	for _, em := range doc.materials {
+		om := gltfOutMaterial{Name: em.name}
+		om.PbrMetallicRoughness = gltfOutPbr{
+			BaseColorFactor: [4]float32{em.color.R, em.color.G, em.color.B, em.color.A},
+			RoughnessFactor: 1,
+		}
+		if em.color.A < 1 {
+			om.AlphaMode = "BLEND"
+		}
+		if em.texture != "" {
+			img, ok := images[em.texture]
+			if !ok {
+				img = len(w.doc.Images)
+				images[em.texture] = img
+				oi, err := w.addImage(em.texture, dir, glb)
+				if err != nil {
+					return err
+				}
+				if glb && oi.URI != "" {
+					doc.warnings = append(doc.warnings, fmt.Sprintf("texture image not embedded, only PNG and JPEG are:%s", em.texture))
+				}
+				w.doc.Images = append(w.doc.Images, oi)
+				w.doc.Textures = append(w.doc.Textures, gltfOutTexture{Source: img})
+			}
+			om.PbrMetallicRoughness.BaseColorTexture = &gltfOutTextureRef{Index: img}
+		}
+		w.doc.Materials = append(w.doc.Materials, om)
+	}
+
+	// Meshes
+	for _, em := range doc.meshes {
+		attribs := map[string]int{}
+		attribs["POSITION"] = w.addFloats(em.positions, "VEC3", gltfArrayBuffer, true)
+		if len(em.normals) > 0 {
+			attribs["NORMAL"] = w.addFloats(em.normals, "VEC3", gltfArrayBuffer, false)
+		}
+		if len(em.uvs) > 0 {
+			uvs := em.uvs
+			if !doc.uvTopDown {
+				uvs = flipV(uvs)
+			}
+			attribs["TEXCOORD_0"] = w.addFloats(uvs, "VEC2", gltfArrayBuffer, false)
+		}
+		om := gltfOutMesh{Name: em.name}
+		for _, prim := range em.prims {
+			om.Primitives = append(om.Primitives, gltfOutPrimitive{
+				Attributes: attribs,
+				Indices:    w.addIndices(prim.indices),
+				Material:   prim.material,
+				Mode:       gltfTriangles,
+			})
+		}
+		w.doc.Meshes = append(w.doc.Meshes, om)
+	}
+
+	// Nodes with their local transforms
+	for _, en := range doc.nodes {
+		on := gltfOutNode{Name: en.name, Children: en.children}
+		if en.mesh >= 0 {
+			mesh := en.mesh
+			on.Mesh = &mesh
+		}
+		if en.pos != (math32.Vector3{}) {
+			on.Translation = &[3]float32{en.pos.X, en.pos.Y, en.pos.Z}
+		}
+		if en.quat != (math32.Quaternion{0, 0, 0, 1}) {
+			on.Rotation = &[4]float32{en.quat.X, en.quat.Y, en.quat.Z, en.quat.W}
+		}
+		if en.scale != (math32.Vector3{1, 1, 1}) {
+			on.Scale = &[3]float32{en.scale.X, en.scale.Y, en.scale.Z}
+		}
+		w.doc.Nodes = append(w.doc.Nodes, on)
+	}
+
+	// Animations, whose keyframe buffer views have no target
+	for _, ea := range doc.animations {
+		oa := gltfOutAnimation{Name: ea.name}
+		for _, es := range ea.samplers {
+			oa.Samplers = append(oa.Samplers, gltfOutAnimationSampler{
+				Input:         w.addFloats(es.times, "SCALAR", 0, true),
+				Output:        w.addFloats(es.values, es.typ, 0, false),
+				Interpolation: es.interpolation,
+			})
+		}
+		for _, ec := range ea.channels {
+			oa.Channels = append(oa.Channels, gltfOutChannel{Sampler: ec.sampler, Target: gltfOutTarget{Node: ec.node, Path: ec.path}})
+		}
+		w.doc.Animations = append(w.doc.Animations, oa)
+	}
+
+	// Buffer, omitted if there is no binary data
+	if w.bin.Len() > 0 {
+		buffer := gltfOutBuffer{ByteLength: w.bin.Len()}
+		if !glb {
+			binPath := strings.TrimSuffix(fpath, filepath.Ext(fpath)) + ".bin"
+			buffer.URI = filepath.Base(binPath)
+			err := ioutil.WriteFile(binPath, w.bin.Bytes(), 0644)
+			if err != nil {
+				return err
+			}
+		}
+		w.doc.Buffers = []gltfOutBuffer{buffer}
+	}
+
+	if !glb {
+		data, err := json.MarshalIndent(&w.doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(fpath, data, 0644)
+	}
+	return w.writeGLB(fpath)
+}
+
+// addFloats adds the specified float values of the specified accessor type to the buffer
+// with their buffer view, which has the specified target if not zero, and accessor and
+// returns the accessor index. If bounds is true the accessor has the minimum and maximum
+// values, which are required for positions and animation keyframe times.
+func (w *gltfWriter) addFloats(values []float32, typ string, target int, bounds bool) int {
+
+	size := gltfTypeComponents[typ]
+	acc := gltfOutAccessor{ComponentType: gltfFloat, Count: len(values) / size, Type: typ}
+	if bounds && len(values) >= size {
+		acc.Min = append([]float32(nil), values[:size]...)
+		acc.Max = append([]float32(nil), values[:size]...)
+		for i := size; i+size <= len(values); i += size {
+			for j := 0; j < size; j++ {
+				acc.Min[j] = float32(math.Min(float64(acc.Min[j]), float64(values[i+j])))
+				acc.Max[j] = float32(math.Max(float64(acc.Max[j]), float64(values[i+j])))
+			}
+		}
+	}
+	acc.BufferView = w.addBufferView(values, target)
+	w.doc.Accessors = append(w.doc.Accessors, acc)
+	return len(w.doc.Accessors) - 1
+}
+
+// addIndices adds the specified triangle indices to the buffer with
+// their buffer view and accessor and returns the accessor index
+func (w *gltfWriter) addIndices(indices []uint32) int {
+
+	acc := gltfOutAccessor{ComponentType: gltfUnsignedInt, Count: len(indices), Type: "SCALAR"}
+	acc.BufferView = w.addBufferView(indices, gltfElementArray)
+	w.doc.Accessors = append(w.doc.Accessors, acc)
+	return len(w.doc.Accessors) - 1
+}
+
+// addImage returns the glTF image of the specified image file, embedded in the buffer
+// if glb is true and the image is PNG or JPEG, or else referenced relative
+// to the specified output directory
+func (w *gltfWriter) addImage(fpath, dir string, glb bool) (gltfOutImage, error) {
+
+	mimeType := glbMimeTypes[strings.ToLower(filepath.Ext(fpath))]
+	if !glb || mimeType == "" {
+		return gltfOutImage{URI: relativeURI(dir, fpath)}, nil
+	}
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return gltfOutImage{}, err
+	}
+	bv := w.addBufferView(data, 0)
+	return gltfOutImage{BufferView: &bv, MimeType: mimeType}, nil
+}
+
+// addBufferView appends the specified slice of fixed size values to the buffer,
+// aligned to 4 bytes, and returns the index of its new buffer view
+func (w *gltfWriter) addBufferView(data interface{}, target int) int {
+
+	for w.bin.Len()%4 != 0 {
+		w.bin.WriteByte(0)
+	}
+	offset := w.bin.Len()
+	binary.Write(&w.bin, binary.LittleEndian, data)
+	w.doc.BufferViews = append(w.doc.BufferViews, gltfOutBufferView{
+		ByteOffset: offset,
+		ByteLength: w.bin.Len() - offset,
+		Target:     target,
+	})
+	return len(w.doc.BufferViews) - 1
+}
+
+// writeGLB writes the document and the buffer as a GLB file
+func (w *gltfWriter) writeGLB(fpath string) error {
+
+	js, err := json.Marshal(&w.doc)
+	if err != nil {
+		return err
+	}
+	// Chunks are padded to 4 bytes, the JSON chunk with spaces
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	bin := w.bin.Bytes()
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	// The binary chunk is omitted if there is no binary data
+	var out bytes.Buffer
+	total := 12 + 8 + len(js)
+	if len(bin) > 0 {
+		total += 8 + len(bin)
+	}
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, 2, uint32(total)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	out.Write(js)
+	if len(bin) > 0 {
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(bin)), glbChunkBIN})
+		out.Write(bin)
+	}
+	return ioutil.WriteFile(fpath, out.Bytes(), 0644)
+}
+
+// flipV returns a copy of the specified texture coordinates with the V coordinate flipped,
+// to convert between the glTF and the OBJ conventions
+func flipV(uvs []float32) []float32 {
+
+	flipped := make([]float32, len(uvs))
+	for i := 0; i+1 < len(uvs); i += 2 {
+		flipped[i] = uvs[i]
+		flipped[i+1] = 1 - uvs[i+1]
+	}
+	return flipped
+}
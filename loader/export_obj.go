@@ -0,0 +1,115 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// writeOBJ writes the specified model as an OBJ file and its MTL material file.
+// OBJ has no node hierarchy, so the vertices are transformed to world coordinates
+// and each mesh is written as an object with the name of its node.
+func writeOBJ(doc *exportDoc, fpath string) error {
+
+	mtlPath := strings.TrimSuffix(fpath, filepath.Ext(fpath)) + ".mtl"
+	err := writeMTL(doc, mtlPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# g3nd convert\nmtllib %s\n", filepath.Base(mtlPath))
+
+	// Next vertex indices of each attribute, starting from 1
+	nextPos, nextUV, nextNormal := 1, 1, 1
+	for i, en := range doc.nodes {
+		if en.mesh < 0 {
+			continue
+		}
+		em := doc.meshes[en.mesh]
+		name := en.name
+		if name == "" {
+			name = fmt.Sprintf("object%d", i)
+		}
+		fmt.Fprintf(w, "o %s\n", name)
+
+		// Vertex attributes in world coordinates
+		var normalMatrix math32.Matrix3
+		normalMatrix.GetNormalMatrix(&en.world)
+		for j := 0; j+2 < len(em.positions); j += 3 {
+			v := math32.Vector3{em.positions[j], em.positions[j+1], em.positions[j+2]}
+			v.ApplyMatrix4(&en.world)
+			fmt.Fprintf(w, "v %g %g %g\n", v.X, v.Y, v.Z)
+		}
+		uvs := em.uvs
+		if doc.uvTopDown {
+			uvs = flipV(uvs)
+		}
+		for j := 0; j+1 < len(uvs); j += 2 {
+			fmt.Fprintf(w, "vt %g %g\n", uvs[j], uvs[j+1])
+		}
+		for j := 0; j+2 < len(em.normals); j += 3 {
+			n := math32.Vector3{em.normals[j], em.normals[j+1], em.normals[j+2]}
+			n.ApplyMatrix3(&normalMatrix).Normalize()
+			fmt.Fprintf(w, "vn %g %g %g\n", n.X, n.Y, n.Z)
+		}
+
+		// Faces of each primitive with its material
+		hasUV, hasNormal := len(em.uvs) > 0, len(em.normals) > 0
+		for _, prim := range em.prims {
+			fmt.Fprintf(w, "usemtl %s\n", doc.materials[prim.material].name)
+			for j := 0; j+2 < len(prim.indices); j += 3 {
+				w.WriteString("f")
+				for k := 0; k < 3; k++ {
+					idx := int(prim.indices[j+k])
+					switch {
+					case hasUV && hasNormal:
+						fmt.Fprintf(w, " %d/%d/%d", nextPos+idx, nextUV+idx, nextNormal+idx)
+					case hasUV:
+						fmt.Fprintf(w, " %d/%d", nextPos+idx, nextUV+idx)
+					case hasNormal:
+						fmt.Fprintf(w, " %d//%d", nextPos+idx, nextNormal+idx)
+					default:
+						fmt.Fprintf(w, " %d", nextPos+idx)
+					}
+				}
+				w.WriteString("\n")
+			}
+		}
+		nextPos += len(em.positions) / 3
+		nextUV += len(em.uvs) / 2
+		nextNormal += len(em.normals) / 3
+	}
+	return w.Flush()
+}
+
+// writeMTL writes the materials of the specified model as an MTL file
+func writeMTL(doc *exportDoc, fpath string) error {
+
+	f, err := os.Create(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	w.WriteString("# g3nd convert\n")
+	dir := filepath.Dir(fpath)
+	for _, em := range doc.materials {
+		fmt.Fprintf(w, "\nnewmtl %s\n", em.name)
+		fmt.Fprintf(w, "Kd %g %g %g\n", em.color.R, em.color.G, em.color.B)
+		fmt.Fprintf(w, "d %g\n", em.color.A)
+		if em.texture != "" {
+			fmt.Fprintf(w, "map_Kd %s\n", relativeURI(dir, em.texture))
+		}
+	}
+	return w.Flush()
+}
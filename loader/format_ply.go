@@ -0,0 +1,420 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/util"
+)
+
+// plyLoader loads PLY files in ASCII or binary format.
+// Files with faces are loaded as meshes and files with only vertices as points.
+type plyLoader struct{}
+
+// Extensions satisfies the util.ModelLoader interface
+func (l *plyLoader) Extensions() []string {
+
+	return []string{".ply"}
+}
+
+// Load satisfies the util.ModelLoader interface
+func (l *plyLoader) Load(fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	md, err := decodePLY(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	return md.newNode(), nil, nil
+}
+
+// plyElement describes an element of a PLY file and its properties
+type plyElement struct {
+	name  string
+	count int
+	props []plyProperty
+}
+
+// plyProperty describes a scalar or list property of a PLY element
+type plyProperty struct {
+	name      string
+	typ       string // Value type
+	countType string // Type of the list count or empty for scalar properties
+}
+
+// plySizes maps the PLY value types to their sizes in bytes
+var plySizes = map[string]int{
+	"char": 1, "uchar": 1, "short": 2, "ushort": 2, "int": 4, "uint": 4, "float": 4, "double": 8,
+	"int8": 1, "uint8": 1, "int16": 2, "uint16": 2, "int32": 4, "uint32": 4, "float32": 4, "float64": 8,
+}
+
+// plyReader reads the values of the body of a PLY file
+type plyReader interface {
+	read(typ string) (float64, error)
+	maxValues(typ string) int // Maximum number of values of the specified type left in the body
+}
+
+// decodePLY decodes the specified PLY file contents.
+// The vertices may have positions, normals and colors (red, green and blue properties),
+// and the faces are lists of vertex indices which are triangulated as fans.
+// Vertex normals are calculated from the faces if not specified in the file.
+func decodePLY(data []byte) (*meshData, error) {
+
+	// Header
+	if !bytes.HasPrefix(data, []byte("ply")) {
+		return nil, fmt.Errorf("invalid PLY file")
+	}
+	end := bytes.Index(data, []byte("end_header"))
+	if end < 0 {
+		return nil, fmt.Errorf("end_header not found")
+	}
+	body := data[end+len("end_header"):]
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	}
+	var format string
+	var elements []*plyElement
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid format line")
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid element line:%s", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("invalid element count:%s", line)
+			}
+			elements = append(elements, &plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return nil, fmt.Errorf("property without element")
+			}
+			var prop plyProperty
+			if len(fields) == 5 && fields[1] == "list" {
+				prop = plyProperty{name: fields[4], typ: fields[3], countType: fields[2]}
+			} else if len(fields) == 3 {
+				prop = plyProperty{name: fields[2], typ: fields[1]}
+			} else {
+				return nil, fmt.Errorf("invalid property line:%s", line)
+			}
+			if plySizes[prop.typ] == 0 || (prop.countType != "" && plySizes[prop.countType] == 0) {
+				return nil, fmt.Errorf("invalid property type:%s", line)
+			}
+			el := elements[len(elements)-1]
+			el.props = append(el.props, prop)
+		}
+	}
+
+	var r plyReader
+	switch format {
+	case "ascii":
+		r = newPlyASCIIReader(body)
+	case "binary_little_endian":
+		r = &plyBinaryReader{r: bytes.NewReader(body), order: binary.LittleEndian}
+	case "binary_big_endian":
+		r = &plyBinaryReader{r: bytes.NewReader(body), order: binary.BigEndian}
+	default:
+		return nil, fmt.Errorf("unsupported format:%s", format)
+	}
+
+	// Body
+	md := new(meshData)
+	nverts := 0
+	for _, el := range elements {
+		// Each record has at least one value of each property,
+		// so counts larger than the rest of the body are invalid
+		if len(el.props) > 0 && el.count > r.maxValues("uchar")/len(el.props) {
+			return nil, fmt.Errorf("element %s: count %d exceeds the file size", el.name, el.count)
+		}
+		var err error
+		switch el.name {
+		case "vertex":
+			err = md.readPLYVertices(r, el)
+			nverts = el.count
+		case "face":
+			err = md.readPLYFaces(r, el, nverts)
+		default:
+			err = skipPLYElement(r, el)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %v", el.name, err)
+		}
+	}
+	if nverts == 0 {
+		return nil, fmt.Errorf("no vertices found")
+	}
+	md.points = len(md.indices) == 0
+	if !md.points && len(md.normals) == 0 {
+		md.normals = vertexNormals(md.positions, md.indices)
+	}
+	return md, nil
+}
+
+// readPLYVertices reads the vertex element with its positions, normals and colors
+func (md *meshData) readPLYVertices(r plyReader, el *plyElement) error {
+
+	// Index of each known property
+	index := map[string]int{}
+	for i, prop := range el.props {
+		if prop.countType == "" {
+			index[prop.name] = i
+		}
+	}
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := index[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	if !has("x", "y", "z") {
+		return fmt.Errorf("vertex positions not found")
+	}
+	hasNormals := has("nx", "ny", "nz")
+	hasColors := has("red", "green", "blue")
+	// Integer colors are in the range 0 to 255
+	colorScale := 1.0
+	if hasColors {
+		typ := el.props[index["red"]].typ
+		if !strings.HasPrefix(typ, "float") && typ != "double" {
+			colorScale = 255
+		}
+	}
+
+	md.positions = math32.NewArrayF32(0, el.count*3)
+	values := make([]float64, len(el.props))
+	for i := 0; i < el.count; i++ {
+		for j, prop := range el.props {
+			v, err := readPLYProperty(r, prop)
+			if err != nil {
+				return err
+			}
+			values[j] = v
+		}
+		md.positions.Append(float32(values[index["x"]]), float32(values[index["y"]]), float32(values[index["z"]]))
+		if hasNormals {
+			md.normals.Append(float32(values[index["nx"]]), float32(values[index["ny"]]), float32(values[index["nz"]]))
+		}
+		if hasColors {
+			md.colors.Append(
+				float32(values[index["red"]]/colorScale),
+				float32(values[index["green"]]/colorScale),
+				float32(values[index["blue"]]/colorScale),
+			)
+		}
+	}
+	return nil
+}
+
+// readPLYFaces reads the face element and triangulates its vertex index lists
+func (md *meshData) readPLYFaces(r plyReader, el *plyElement, nverts int) error {
+
+	for i := 0; i < el.count; i++ {
+		for _, prop := range el.props {
+			if prop.countType == "" || (prop.name != "vertex_indices" && prop.name != "vertex_index") {
+				_, err := readPLYProperty(r, prop)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			n, err := readPLYCount(r, prop)
+			if err != nil {
+				return err
+			}
+			face := make([]uint32, n)
+			for j := range face {
+				v, err := r.read(prop.typ)
+				if err != nil {
+					return err
+				}
+				if v != math.Trunc(v) || v < 0 || v >= float64(nverts) {
+					return fmt.Errorf("face %d: vertex index %d out of range", i, int(v))
+				}
+				face[j] = uint32(v)
+			}
+			for j := 2; j < len(face); j++ {
+				md.indices.Append(face[0], face[j-1], face[j])
+			}
+		}
+	}
+	return nil
+}
+
+// skipPLYElement reads and discards the values of an unknown element
+func skipPLYElement(r plyReader, el *plyElement) error {
+
+	for i := 0; i < el.count; i++ {
+		for _, prop := range el.props {
+			_, err := readPLYProperty(r, prop)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readPLYProperty reads the value of a scalar property or the values of a list
+// property, returning the value of the scalar or the list count
+func readPLYProperty(r plyReader, prop plyProperty) (float64, error) {
+
+	if prop.countType == "" {
+		return r.read(prop.typ)
+	}
+	n, err := readPLYCount(r, prop)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < n; i++ {
+		_, err := r.read(prop.typ)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return float64(n), nil
+}
+
+// readPLYCount reads the count of a list property and checks that it is
+// not negative and that the rest of the body may contain its values
+func readPLYCount(r plyReader, prop plyProperty) (int, error) {
+
+	n, err := r.read(prop.countType)
+	if err != nil {
+		return 0, err
+	}
+	if n != math.Trunc(n) || n < 0 || n > float64(r.maxValues(prop.typ)) {
+		return 0, fmt.Errorf("invalid list count:%v", n)
+	}
+	return int(n), nil
+}
+
+// plyASCIIReader reads the values of an ASCII body separated by white space
+type plyASCIIReader struct {
+	body []byte
+	pos  int // Position of the next value
+}
+
+// newPlyASCIIReader returns a reader for the specified ASCII body
+func newPlyASCIIReader(body []byte) *plyASCIIReader {
+
+	return &plyASCIIReader{body: body}
+}
+
+// read satisfies the plyReader interface
+func (r *plyASCIIReader) read(typ string) (float64, error) {
+
+	for r.pos < len(r.body) && isSpace(r.body[r.pos]) {
+		r.pos++
+	}
+	start := r.pos
+	for r.pos < len(r.body) && !isSpace(r.body[r.pos]) {
+		r.pos++
+	}
+	if start == r.pos {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return strconv.ParseFloat(string(r.body[start:r.pos]), 64)
+}
+
+// maxValues satisfies the plyReader interface.
+// Values have at least one character and are separated by white space.
+func (r *plyASCIIReader) maxValues(typ string) int {
+
+	return (len(r.body) - r.pos + 1) / 2
+}
+
+// isSpace returns if the specified character is ASCII white space
+func isSpace(c byte) bool {
+
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// plyBinaryReader reads the values of a binary body with the specified byte order
+type plyBinaryReader struct {
+	r     *bytes.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+// maxValues satisfies the plyReader interface
+func (r *plyBinaryReader) maxValues(typ string) int {
+
+	return r.r.Len() / plySizes[typ]
+}
+
+// read satisfies the plyReader interface
+func (r *plyBinaryReader) read(typ string) (float64, error) {
+
+	b := r.buf[:plySizes[typ]]
+	_, err := io.ReadFull(r.r, b)
+	if err != nil {
+		return 0, err
+	}
+	switch typ {
+	case "char", "int8":
+		return float64(int8(b[0])), nil
+	case "uchar", "uint8":
+		return float64(b[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(b))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(b)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(b))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(b)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(b)), nil
+	}
+}
+
+// vertexNormals returns the vertex normals of the specified indexed triangles,
+// averaging the normals of the faces which share each vertex
+func vertexNormals(positions math32.ArrayF32, indices math32.ArrayU32) math32.ArrayF32 {
+
+	normals := math32.NewArrayF32(len(positions), len(positions))
+	var a, b, c, n math32.Vector3
+	for i := 0; i+2 < len(indices); i += 3 {
+		ia, ib, ic := int(indices[i])*3, int(indices[i+1])*3, int(indices[i+2])*3
+		a.Set(positions[ia], positions[ia+1], positions[ia+2])
+		b.Set(positions[ib], positions[ib+1], positions[ib+2])
+		c.Set(positions[ic], positions[ic+1], positions[ic+2])
+		b.Sub(&a)
+		c.Sub(&a)
+		n.CrossVectors(&b, &c)
+		for _, idx := range []int{ia, ib, ic} {
+			normals[idx] += n.X
+			normals[idx+1] += n.Y
+			normals[idx+2] += n.Z
+		}
+	}
+	for i := 0; i+2 < len(normals); i += 3 {
+		n.Set(normals[i], normals[i+1], normals[i+2]).Normalize()
+		normals[i], normals[i+1], normals[i+2] = n.X, n.Y, n.Z
+	}
+	return normals
+}
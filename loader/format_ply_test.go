@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodePLY(t *testing.T) {
+
+	tests := []struct {
+		file       string
+		vertices   int
+		indices    int
+		points     bool
+		firstColor [3]float32 // Color of the first vertex scaled from 0..255
+	}{
+		{file: "ply/cube_colors.ply", vertices: 8, indices: 36, firstColor: [3]float32{0, 0, 0}},
+		{file: "ply/sphere_points.ply", vertices: 288, points: true, firstColor: [3]float32{144.0 / 255, 253.0 / 255, 127.0 / 255}},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			md, err := decodePLY(readFixture(t, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(md.positions) / 3; got != test.vertices {
+				t.Errorf("got %d vertices, want %d", got, test.vertices)
+			}
+			if len(md.indices) != test.indices {
+				t.Errorf("got %d indices, want %d", len(md.indices), test.indices)
+			}
+			if md.points != test.points {
+				t.Errorf("got points %v, want %v", md.points, test.points)
+			}
+			if len(md.colors) != len(md.positions) {
+				t.Fatalf("got %d color values for %d position values", len(md.colors), len(md.positions))
+			}
+			checkVector(t, "color", md.colors, 0, test.firstColor)
+			for i, v := range md.colors {
+				if v < 0 || v > 1 {
+					t.Fatalf("color value %d out of range: %v", i, v)
+				}
+			}
+			if test.points {
+				if len(md.normals) != 0 {
+					t.Errorf("got %d normal values for a point cloud", len(md.normals))
+				}
+				return
+			}
+
+			// The normals of the cube corners are calculated from the faces and point outwards
+			if len(md.normals) != len(md.positions) {
+				t.Fatalf("got %d normal values for %d position values", len(md.normals), len(md.positions))
+			}
+			for i := 0; i < test.vertices; i++ {
+				p, n := md.positions[i*3:i*3+3], md.normals[i*3:i*3+3]
+				if p[0]*n[0] <= 0 || p[1]*n[1] <= 0 || p[2]*n[2] <= 0 {
+					t.Errorf("normal of vertex %d at %v does not point outwards: %v", i, p, n)
+				}
+				if length := n[0]*n[0] + n[1]*n[1] + n[2]*n[2]; !near(length, 1) {
+					t.Errorf("normal of vertex %d is not normalized: %v", i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodePLYInvalid(t *testing.T) {
+
+	const vertexHeader = "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n"
+	const vertices = "0 0 0\n1 0 0\n0 1 0\n"
+	const faceHeader = "element face 1\nproperty list uchar int vertex_indices\nend_header\n"
+
+	// Binary face with an int list count of -1
+	var negative bytes.Buffer
+	negative.WriteString("ply\nformat binary_little_endian 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n")
+	negative.WriteString("element face 1\nproperty list int int vertex_indices\nend_header\n")
+	binary.Write(&negative, binary.LittleEndian, make([]float32, 9))
+	binary.Write(&negative, binary.LittleEndian, int32(-1))
+
+	// Binary face with a huge list count
+	var huge bytes.Buffer
+	huge.WriteString("ply\nformat binary_little_endian 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n")
+	huge.WriteString("element face 1\nproperty list uint int vertex_indices\nend_header\n")
+	binary.Write(&huge, binary.LittleEndian, make([]float32, 9))
+	binary.Write(&huge, binary.LittleEndian, []uint32{0x7FFFFFFF, 0, 1, 2})
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"not ply", "solid s\n"},
+		{"no end_header", "ply\nformat ascii 1.0\nelement vertex 1\n"},
+		{"unsupported format", "ply\nformat binary_middle_endian 1.0\nelement vertex 1\nproperty float x\nend_header\n"},
+		{"invalid type", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float3 x\nend_header\n0\n"},
+		{"negative element count", "ply\nformat ascii 1.0\nelement vertex -1\nproperty float x\nend_header\n"},
+		{"element count exceeds file", "ply\nformat ascii 1.0\nelement vertex 1000000000\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n"},
+		{"no positions", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\n0\n"},
+		{"no vertices", "ply\nformat ascii 1.0\nelement vertex 0\nproperty float x\nproperty float y\nproperty float z\nend_header\n"},
+		{"truncated vertices", vertexHeader + "end_header\n0 0 0\n1 0\n"},
+		{"invalid number", vertexHeader + "end_header\n0 0 0\n1 0 x\n0 1 0\n"},
+		{"negative list count", vertexHeader + faceHeader + vertices + "-1 0 1 2\n"},
+		{"fractional list count", vertexHeader + faceHeader + vertices + "2.5 0 1 2\n"},
+		{"huge list count", vertexHeader + faceHeader + vertices + "200 0 1 2\n"},
+		{"index out of range", vertexHeader + faceHeader + vertices + "3 0 1 3\n"},
+		{"negative index", vertexHeader + faceHeader + vertices + "3 0 1 -1\n"},
+		{"binary negative list count", negative.String()},
+		{"binary huge list count", huge.String()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodePLY([]byte(test.data))
+			if err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+
+	// Binary point cloud without its last vertex
+	data := readFixture(t, "ply/sphere_points.ply")
+	_, err := decodePLY(data[:len(data)-15])
+	if err == nil {
+		t.Error("truncated binary file: got no error")
+	}
+}
@@ -0,0 +1,224 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/util"
+)
+
+// stlLoader loads STL files in ASCII or binary format
+type stlLoader struct{}
+
+// Extensions satisfies the util.ModelLoader interface
+func (l *stlLoader) Extensions() []string {
+
+	return []string{".stl"}
+}
+
+// Load satisfies the util.ModelLoader interface
+func (l *stlLoader) Load(fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	md, err := decodeSTL(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", fpath, err)
+	}
+	return md.newNode(), nil, nil
+}
+
+// meshData contains the vertex attributes decoded from STL and PLY files
+type meshData struct {
+	positions math32.ArrayF32
+	normals   math32.ArrayF32 // Vertex normals or empty
+	colors    math32.ArrayF32 // Vertex RGB colors or empty
+	indices   math32.ArrayU32 // Triangle indices or empty if the vertices are not indexed
+	points    bool            // The vertices are a point cloud without faces
+}
+
+// newGeometry returns a new geometry with the decoded vertex attributes
+func (md *meshData) newGeometry() *geometry.Geometry {
+
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(md.positions).AddAttrib(gls.VertexPosition))
+	if len(md.normals) > 0 {
+		geom.AddVBO(gls.NewVBO(md.normals).AddAttrib(gls.VertexNormal))
+	}
+	if len(md.colors) > 0 {
+		geom.AddVBO(gls.NewVBO(md.colors).AddAttrib(gls.VertexColor))
+	}
+	if len(md.indices) > 0 {
+		geom.SetIndices(md.indices)
+	}
+	return geom
+}
+
+// newNode returns a new graphic for the decoded data: points for point clouds,
+// an unlit mesh with the vertex colors for colored meshes, or a gray standard mesh
+func (md *meshData) newNode() core.INode {
+
+	geom := md.newGeometry()
+	if md.points {
+		mat := material.NewPoint(&math32.Color{0.2, 0.2, 0.2})
+		mat.SetSize(20)
+		return graphic.NewPoints(geom, mat)
+	}
+	if len(md.colors) > 0 {
+		mat := material.NewBasic()
+		mat.SetSide(material.SideDouble)
+		return graphic.NewMesh(geom, mat)
+	}
+	mat := material.NewStandard(&math32.Color{0.7, 0.7, 0.7})
+	mat.SetSide(material.SideDouble)
+	return graphic.NewMesh(geom, mat)
+}
+
+// decodeSTL decodes the specified STL file contents in ASCII or binary format.
+// The triangles are not indexed and their vertices have the face normals,
+// which are calculated from the vertices if not specified in the file.
+// The colors of binary files are decoded from the triangle attributes
+// using the VisCAM/SolidView convention: 5 bits for each of the blue,
+// green and red components and bit 15 set if the color is valid.
+func decodeSTL(data []byte) (*meshData, error) {
+
+	// Binary files may also start with "solid", so the size is checked first
+	if len(data) >= 84 {
+		count := binary.LittleEndian.Uint32(data[80:84])
+		if uint64(len(data)) == 84+50*uint64(count) {
+			return decodeSTLBinary(data[84:], int(count)), nil
+		}
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+		return decodeSTLASCII(data)
+	}
+	return nil, fmt.Errorf("invalid STL file")
+}
+
+// decodeSTLBinary decodes the specified number of 50 byte triangle records
+func decodeSTLBinary(data []byte, count int) *meshData {
+
+	md := new(meshData)
+	md.positions = math32.NewArrayF32(0, count*9)
+	md.normals = math32.NewArrayF32(0, count*9)
+	colors := math32.NewArrayF32(0, count*9)
+	hasColors := false
+	for i := 0; i < count; i++ {
+		rec := data[i*50 : i*50+50]
+		var v [12]float32
+		for j := range v {
+			v[j] = math.Float32frombits(binary.LittleEndian.Uint32(rec[j*4:]))
+		}
+		md.addTriangle(v[0:3], v[3:12])
+		attr := binary.LittleEndian.Uint16(rec[48:])
+		r, g, b := float32(1), float32(1), float32(1)
+		if attr&0x8000 != 0 {
+			hasColors = true
+			r = float32((attr>>10)&0x1F) / 31
+			g = float32((attr>>5)&0x1F) / 31
+			b = float32(attr&0x1F) / 31
+		}
+		colors.Append(r, g, b, r, g, b, r, g, b)
+	}
+	if hasColors {
+		md.colors = colors
+	}
+	return md
+}
+
+// decodeSTLASCII decodes the facets of an ASCII STL file
+func decodeSTLASCII(data []byte) (*meshData, error) {
+
+	md := new(meshData)
+	var normal, verts []float32
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "facet":
+			if len(fields) != 5 || fields[1] != "normal" {
+				return nil, fmt.Errorf("line %d: invalid facet", lineno)
+			}
+			values, err := parseFloats(fields[2:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineno, err)
+			}
+			normal, verts = values, verts[:0]
+		case "vertex":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("line %d: invalid vertex", lineno)
+			}
+			values, err := parseFloats(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineno, err)
+			}
+			verts = append(verts, values...)
+		case "endfacet":
+			if normal == nil || len(verts) != 9 {
+				return nil, fmt.Errorf("line %d: facet without 3 vertices", lineno)
+			}
+			md.addTriangle(normal, verts)
+			normal = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(md.positions) == 0 {
+		return nil, fmt.Errorf("no facets found")
+	}
+	return md, nil
+}
+
+// addTriangle appends the specified triangle vertices with the specified face normal,
+// which is calculated from the vertices if it is zero
+func (md *meshData) addTriangle(normal, verts []float32) {
+
+	n := math32.Vector3{normal[0], normal[1], normal[2]}
+	if n.LengthSq() == 0 {
+		a := math32.Vector3{verts[0], verts[1], verts[2]}
+		b := math32.Vector3{verts[3], verts[4], verts[5]}
+		c := math32.Vector3{verts[6], verts[7], verts[8]}
+		b.Sub(&a)
+		c.Sub(&a)
+		n.CrossVectors(&b, &c)
+	}
+	n.Normalize()
+	md.positions.Append(verts...)
+	for i := 0; i < 3; i++ {
+		md.normals.Append(n.X, n.Y, n.Z)
+	}
+}
+
+// parseFloats parses the specified strings as float values
+func parseFloats(fields []string) ([]float32, error) {
+
+	values := make([]float32, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(v)
+	}
+	return values, nil
+}
@@ -0,0 +1,117 @@
+package loader
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// readFixture returns the contents of the specified file of the data directory
+func readFixture(t *testing.T, name string) []byte {
+
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("..", "data", filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// near returns if the specified values are equal within the float32 precision of the fixtures
+func near(a, b float32) bool {
+
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+// checkVector checks the 3 component vector of the specified array at the specified vertex
+func checkVector(t *testing.T, what string, values []float32, vertex int, want [3]float32) {
+
+	t.Helper()
+	if len(values) < vertex*3+3 {
+		t.Errorf("%s: vertex %d not found in %d values", what, vertex, len(values))
+		return
+	}
+	got := values[vertex*3 : vertex*3+3]
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Errorf("%s of vertex %d: got %v, want %v", what, vertex, got, want)
+			return
+		}
+	}
+}
+
+func TestDecodeSTL(t *testing.T) {
+
+	const c = 0.57735026 // 1/sqrt(3)
+	tests := []struct {
+		file      string
+		vertices  int
+		colors    bool
+		normal    [3]float32 // Normal of the last vertex, calculated in the ASCII file
+		lastColor [3]float32 // Color of the last vertex if colors is true
+	}{
+		{file: "stl/tetrahedron_ascii.stl", vertices: 12, normal: [3]float32{c, c, c}},
+		{file: "stl/tetrahedron_binary.stl", vertices: 12, colors: true, normal: [3]float32{c, c, c}, lastColor: [3]float32{1, 1, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			md, err := decodeSTL(readFixture(t, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(md.positions) / 3; got != test.vertices {
+				t.Errorf("got %d vertices, want %d", got, test.vertices)
+			}
+			if len(md.normals) != len(md.positions) {
+				t.Errorf("got %d normal values for %d position values", len(md.normals), len(md.positions))
+			}
+			if len(md.indices) != 0 || md.points {
+				t.Errorf("got %d indices and points %v, want unindexed triangles", len(md.indices), md.points)
+			}
+			checkVector(t, "normal", md.normals, test.vertices-1, test.normal)
+			if !test.colors {
+				if len(md.colors) != 0 {
+					t.Errorf("got %d color values, want none", len(md.colors))
+				}
+				return
+			}
+			if len(md.colors) != len(md.positions) {
+				t.Fatalf("got %d color values for %d position values", len(md.colors), len(md.positions))
+			}
+			checkVector(t, "color", md.colors, 0, [3]float32{1, 0, 0})
+			checkVector(t, "color", md.colors, test.vertices-1, test.lastColor)
+		})
+	}
+}
+
+func TestDecodeSTLInvalid(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"not stl", "ply\nformat ascii 1.0\n"},
+		{"no facets", "solid empty\nendsolid empty\n"},
+		{"short facet normal", "solid s\nfacet normal 0 0\nendfacet\nendsolid s\n"},
+		{"invalid number", "solid s\nfacet normal 0 0 x\nendfacet\nendsolid s\n"},
+		{"two vertices", "solid s\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\nendfacet\nendsolid s\n"},
+		{"vertex outside facet", "solid s\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nendfacet\nendsolid s\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeSTL([]byte(test.data))
+			if err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+
+	// Binary file with one triangle less than its count, which is not valid ASCII either
+	data := readFixture(t, "stl/tetrahedron_binary.stl")
+	_, err := decodeSTL(data[:len(data)-50])
+	if err == nil {
+		t.Error("truncated binary file: got no error")
+	}
+}
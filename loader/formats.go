@@ -0,0 +1,460 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/collada"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/loader/obj"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/util"
+)
+
+func init() {
+	util.RegisterModelLoader(func() util.ModelLoader { return new(objLoader) })
+	util.RegisterModelLoader(func() util.ModelLoader { return new(colladaLoader) })
+	util.RegisterModelLoader(func() util.ModelLoader { return new(gltfLoader) })
+	util.RegisterModelLoader(func() util.ModelLoader { return new(stlLoader) })
+	util.RegisterModelLoader(func() util.ModelLoader { return new(plyLoader) })
+}
+
+// objLoader loads Wavefront OBJ files with their MTL material files
+type objLoader struct {
+	warnings []string                      // Problems found in the loaded file
+	dec      *obj.Decoder                  // Decoder of the loaded file
+	group    *core.Node                    // Last loaded model
+	dir      string                        // Directory of the loaded file
+	textures map[*texture.Texture2D]string // Image files of the textures, built when requested
+}
+
+// Extensions satisfies the util.ModelLoader interface
+func (l *objLoader) Extensions() []string {
+
+	return []string{".obj"}
+}
+
+// Load satisfies the util.ModelLoader interface
+func (l *objLoader) Load(fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	// Decodes obj file and associated mtl file
+	dec, err := obj.Decode(fpath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Replaces the texture paths by the files found in the texture search paths,
+	// relative to the model directory where the decoder looks for them.
+	// The missing textures are removed and replaced by the placeholder texture
+	// after the meshes are created.
+	dir := filepath.Dir(fpath)
+	textures := newTextureResolver(fpath)
+	missing := make(map[*obj.Material]bool)
+	for _, desc := range dec.Materials {
+		if desc.MapKd != "" {
+			desc.MapKd = textures.resolve(desc.MapKd, dir)
+			missing[desc] = desc.MapKd == ""
+		}
+	}
+	l.warnings = textures.warnings
+
+	// Creates a new node with all the objects in the decoded file
+	group, err := dec.NewGroup()
+	if err != nil {
+		return nil, nil, err
+	}
+	l.dec, l.group, l.dir = dec, group, dir
+	l.forMaterials(func(desc *obj.Material, mat *material.Material) {
+		if missing[desc] {
+			mat.AddTexture(newPlaceholderTexture())
+		}
+	})
+	return group, nil, nil
+}
+
+// forMaterials calls the specified function for each material of the loaded meshes
+// with the description of the decoded material.
+// The decoder creates one mesh for each object with the materials of its faces
+// in the order they are first used, so the materials are matched with the
+// descriptions in the same order.
+func (l *objLoader) forMaterials(f func(desc *obj.Material, mat *material.Material)) {
+
+	children := l.group.Children()
+	for i := 0; i < len(l.dec.Objects) && i < len(children); i++ {
+		mesh, ok := children[i].(*graphic.Mesh)
+		if !ok {
+			continue
+		}
+		var names []string
+		used := make(map[string]bool)
+		for _, face := range l.dec.Objects[i].Faces {
+			if !used[face.Material] {
+				used[face.Material] = true
+				names = append(names, face.Material)
+			}
+		}
+		for j, gm := range mesh.Materials() {
+			if j >= len(names) {
+				break
+			}
+			if desc := l.dec.Materials[names[j]]; desc != nil {
+				f(desc, gm.GetMaterial().GetMaterial())
+			}
+		}
+	}
+}
+
+// Warnings satisfies the Diagnostics interface
+func (l *objLoader) Warnings() []string {
+
+	return l.warnings
+}
+
+// TextureSource satisfies the TextureSources interface.
+// The textures are matched with the diffuse maps of the materials.
+func (l *objLoader) TextureSource(tex *texture.Texture2D) string {
+
+	if l.textures == nil && l.dec != nil {
+		l.textures = make(map[*texture.Texture2D]string)
+		l.forMaterials(func(desc *obj.Material, mat *material.Material) {
+			if textures := mat.Textures(); desc.MapKd != "" && len(textures) > 0 {
+				l.textures[textures[0]] = filepath.Join(l.dir, desc.MapKd)
+			}
+		})
+	}
+	return l.textures[tex]
+}
+
+// colladaLoader loads Collada files and their animations
+type colladaLoader struct {
+	warnings []string // Problems found in the loaded file
+}
+
+// Extensions satisfies the util.ModelLoader interface
+func (l *colladaLoader) Extensions() []string {
+
+	return []string{".dae"}
+}
+
+// Load satisfies the util.ModelLoader interface
+func (l *colladaLoader) Load(fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	return l.LoadContext(context.Background(), fpath)
+}
+
+// LoadContext satisfies the ContextLoader interface
+func (l *colladaLoader) LoadContext(ctx context.Context, fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	// Decodes collada file
+	dec, err := collada.Decode(fpath)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	// Replaces the image paths by the files found in the texture search paths,
+	// as the files often have absolute paths of the machine where they were exported.
+	// The decoder only loads images from files, so the missing ones are replaced by
+	// a placeholder file which is removed when the scene is loaded.
+	textures := newTextureResolver(fpath)
+	defer textures.cleanup()
+	if lib := dec.Dom.LibraryImages; lib != nil {
+		for i := range lib.Image {
+			if src, ok := lib.Image[i].ImageSource.(collada.InitFrom); ok {
+				if uri := textures.resolve(src.Uri, ""); uri != "" {
+					src.Uri = uri
+				} else if uri, err := textures.placeholderFile(); err == nil {
+					src.Uri = uri
+				} else {
+					textures.warnings = append(textures.warnings, fmt.Sprintf("placeholder texture not created:%s", err))
+				}
+				lib.Image[i].ImageSource = src
+			}
+		}
+	}
+	l.warnings = textures.warnings
+
+	// Loads collada scene
+	s, err := dec.NewScene()
+	if err != nil {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	// Checks for animations
+	var anims []util.ModelAnimation
+	ats, err := dec.NewAnimationTargets(s)
+	if err == nil {
+		names := []string{}
+		for name := range ats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			at := ats[name]
+			at.SetStart(-1.0)
+			at.Reset()
+			at.SetLoop(true)
+			anims = append(anims, &clip{ModelAnimation: at, name: name})
+		}
+	}
+	return s, anims, nil
+}
+
+// Warnings satisfies the Diagnostics interface
+func (l *colladaLoader) Warnings() []string {
+
+	return l.warnings
+}
+
+// gltfLoader loads glTF files in JSON (.gltf) or binary (.glb) formats and their animations
+type gltfLoader struct {
+	warnings    []string                      // Problems found in the loaded file
+	targetNames map[string][]string           // Morph target names by mesh and node name of the loaded file
+	issues      []app.ValidationIssue         // Validation problems of the loaded file
+	doc         *gltf.GLTF                    // Loaded document
+	dir         string                        // Directory of the loaded file
+	textures    map[*texture.Texture2D]string // Image files of the textures, built when requested
+}
+
+// Extensions satisfies the util.ModelLoader interface
+func (l *gltfLoader) Extensions() []string {
+
+	return []string{".gltf", ".glb"}
+}
+
+// Load satisfies the util.ModelLoader interface
+func (l *gltfLoader) Load(fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	return l.LoadContext(context.Background(), fpath)
+}
+
+// LoadContext satisfies the ContextLoader interface
+func (l *gltfLoader) LoadContext(ctx context.Context, fpath string) (core.INode, []util.ModelAnimation, error) {
+
+	// Parses file
+	var g *gltf.GLTF
+	var err error
+	if strings.ToLower(filepath.Ext(fpath)) == ".glb" {
+		g, err = gltf.ParseBin(fpath)
+	} else {
+		g, err = gltf.ParseJSON(fpath)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	l.issues = validateGLTF(g, filepath.Dir(fpath))
+	if err := gltfValidationError(l.issues); err != nil {
+		return nil, nil, err
+	}
+	l.doc, l.dir = g, filepath.Dir(fpath)
+
+	// Replaces the image URIs by the files found in the texture search paths,
+	// relative to the model directory where the document looks for them,
+	// and the missing ones by the placeholder image embedded in a data URI
+	textures := newTextureResolver(fpath)
+	for i := range g.Images {
+		if uri := g.Images[i].Uri; uri != "" && !strings.HasPrefix(uri, "data:") {
+			if g.Images[i].Uri = textures.resolve(uri, l.dir); g.Images[i].Uri == "" {
+				if g.Images[i].Uri, err = placeholderURI(); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	// Creates default scene
+	defaultSceneIdx := 0
+	if g.Scene != nil {
+		defaultSceneIdx = *g.Scene
+	}
+	n, err := g.LoadScene(defaultSceneIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	// Creates animations
+	var anims []util.ModelAnimation
+	for i := range g.Animations {
+		// Animations which cannot be loaded are skipped
+		anim, err := g.LoadAnimation(i)
+		if err != nil {
+			continue
+		}
+		anim.SetLoop(true)
+		name := g.Animations[i].Name
+		if name == "" {
+			name = fmt.Sprintf("animation %d", i)
+		}
+		anims = append(anims, &clip{ModelAnimation: anim, name: name, duration: gltfAnimationDuration(g, i)})
+	}
+	l.warnings = append(textures.warnings, gltfUnreferencedTextures(g)...)
+	l.targetNames = gltfTargetNames(g)
+	return n, anims, nil
+}
+
+// gltfValidationError returns an error describing the validation errors of a document,
+// which the engine could not load safely, or nil if there are only warnings
+func gltfValidationError(issues []app.ValidationIssue) error {
+
+	var first *app.ValidationIssue
+	count := 0
+	for i := range issues {
+		if issues[i].Severity == app.SeverityError {
+			if first == nil {
+				first = &issues[i]
+			}
+			count++
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid glTF file: %d validation error(s), first at %s: %s", count, first.Path, first.Message)
+}
+
+// Warnings satisfies the Diagnostics interface
+func (l *gltfLoader) Warnings() []string {
+
+	return l.warnings
+}
+
+// Issues satisfies the Validation interface
+func (l *gltfLoader) Issues() []app.ValidationIssue {
+
+	return l.issues
+}
+
+// TextureSource satisfies the TextureSources interface.
+// The textures are matched with the ones cached by the document for each glTF texture
+// with an external image file.
+func (l *gltfLoader) TextureSource(tex *texture.Texture2D) string {
+
+	if l.textures == nil && l.doc != nil {
+		l.textures = make(map[*texture.Texture2D]string)
+		for i, t := range l.doc.Textures {
+			if t.Source == nil || *t.Source >= len(l.doc.Images) {
+				continue
+			}
+			uri := l.doc.Images[*t.Source].Uri
+			if uri == "" || strings.HasPrefix(uri, "data:") {
+				continue
+			}
+			loaded, err := l.doc.LoadTexture(i)
+			if err == nil {
+				l.textures[loaded] = filepath.Join(l.dir, uri)
+			}
+		}
+	}
+	return l.textures[tex]
+}
+
+// TargetNames satisfies the MorphNames interface
+func (l *gltfLoader) TargetNames(name string) []string {
+
+	if name == "" {
+		return nil
+	}
+	return l.targetNames[name]
+}
+
+// gltfTargetNames returns the morph target names of the meshes, which by convention
+// are in the "targetNames" array of the mesh extras, mapped by the names
+// of the meshes and of the nodes which use them
+func gltfTargetNames(g *gltf.GLTF) map[string][]string {
+
+	names := make(map[string][]string)
+	meshNames := make(map[int][]string)
+	for i, m := range g.Meshes {
+		extras, ok := interface{}(m.Extras).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		list, ok := extras["targetNames"].([]interface{})
+		if !ok {
+			continue
+		}
+		var targets []string
+		for _, item := range list {
+			s, _ := item.(string)
+			targets = append(targets, s)
+		}
+		meshNames[i] = targets
+		if m.Name != "" {
+			names[m.Name] = targets
+		}
+	}
+	for _, node := range g.Nodes {
+		if node.Mesh == nil || node.Name == "" {
+			continue
+		}
+		if targets, ok := meshNames[*node.Mesh]; ok {
+			names[node.Name] = targets
+		}
+	}
+	return names
+}
+
+// gltfAnimationDuration returns the duration of the specified animation
+// from the maximum values of the accessors of its keyframe times
+func gltfAnimationDuration(g *gltf.GLTF, idx int) float32 {
+
+	duration := float32(0)
+	for _, sampler := range g.Animations[idx].Samplers {
+		acc := g.Accessors[sampler.Input]
+		if len(acc.Max) > 0 && acc.Max[0] > duration {
+			duration = acc.Max[0]
+		}
+	}
+	return duration
+}
+
+// gltfUnreferencedTextures returns warnings for the textures which are not used by any material
+func gltfUnreferencedTextures(g *gltf.GLTF) []string {
+
+	used := make(map[int]bool)
+	for _, m := range g.Materials {
+		if pbr := m.PbrMetallicRoughness; pbr != nil {
+			if pbr.BaseColorTexture != nil {
+				used[pbr.BaseColorTexture.Index] = true
+			}
+			if pbr.MetallicRoughnessTexture != nil {
+				used[pbr.MetallicRoughnessTexture.Index] = true
+			}
+		}
+		if m.NormalTexture != nil {
+			used[m.NormalTexture.Index] = true
+		}
+		if m.OcclusionTexture != nil {
+			used[m.OcclusionTexture.Index] = true
+		}
+		if m.EmissiveTexture != nil {
+			used[m.EmissiveTexture.Index] = true
+		}
+	}
+	var warnings []string
+	for i := range g.Textures {
+		if !used[i] {
+			warnings = append(warnings, fmt.Sprintf("texture #%d is not referenced by any material", i))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,31 @@
+package loader
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/window"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/util"
+)
+
+// addFrameControls adds a button to the control folder and subscribes to the F key
+// to frame the camera on the model returned by the specified function
+func addFrameControls(a *app.App, model func() core.INode) {
+
+	frame := func() {
+		if m := model(); m != nil {
+			util.FrameNode(a.Camera(), m)
+		}
+	}
+	if a.ControlFolder() != nil {
+		b := gui.NewButton("Frame (F)")
+		b.Subscribe(gui.OnClick, func(evname string, ev interface{}) { frame() })
+		a.ControlFolder().AddPanel(b)
+	}
+	a.Window().Subscribe(window.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Keycode == window.KeyF && kev.Mods == 0 {
+			frame()
+		}
+	})
+}
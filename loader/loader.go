@@ -0,0 +1,68 @@
+package loader
+
+import (
+	"context"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/util"
+)
+
+// ContextLoader is the optional interface of loaders which stop loading,
+// between the steps of a file, when the specified context is cancelled
+type ContextLoader interface {
+	LoadContext(ctx context.Context, fpath string) (core.INode, []util.ModelAnimation, error)
+}
+
+// Diagnostics is the optional interface of loaders which report
+// problems found in their loaded file
+type Diagnostics interface {
+	Warnings() []string
+}
+
+// Validation is the optional interface of loaders which validate the files
+// before loading them and report the problems found in their file,
+// even if it could not be loaded
+type Validation interface {
+	Issues() []app.ValidationIssue
+}
+
+// NamedAnimation is the optional interface of animations with the name read from the model file
+type NamedAnimation interface {
+	Name() string
+}
+
+// TimedAnimation is the optional interface of animations with a known duration
+type TimedAnimation interface {
+	Duration() float32 // Duration in seconds or zero if unknown
+}
+
+// clip is an animation with the name and duration read from the model file,
+// which satisfies the NamedAnimation and TimedAnimation interfaces
+type clip struct {
+	util.ModelAnimation
+	name     string  // Animation name
+	duration float32 // Duration in seconds or zero if unknown
+}
+
+// Name returns the name of the animation
+func (c *clip) Name() string {
+
+	return c.name
+}
+
+// Duration returns the duration of the animation in seconds or zero if unknown
+func (c *clip) Duration() float32 {
+
+	return c.duration
+}
+
+// fileFilters returns the file selection filters for the specified extensions
+func fileFilters(exts []string) []string {
+
+	filters := []string{}
+	for _, ext := range exts {
+		filters = append(filters, "*"+ext)
+	}
+	return filters
+}
@@ -0,0 +1,227 @@
+package loader
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/g3nd/util"
+)
+
+// MorphNames is the optional interface of loaders which know the names of the
+// morph targets of the meshes of their loaded file
+type MorphNames interface {
+	TargetNames(name string) []string // Target names of the mesh or node with the specified name or nil
+}
+
+// morphNameMap maps mesh and node names to the names of their morph targets
+type morphNameMap map[string][]string
+
+// newMorphNameMap returns the morph target names known by the specified loader
+// for the meshes of the specified model and their ancestors
+func newMorphNameMap(names MorphNames, model core.INode) morphNameMap {
+
+	m := make(morphNameMap)
+	if names == nil {
+		return m
+	}
+	nodes, _ := findMorphMeshes(model)
+	for _, inode := range nodes {
+		for node := inode.GetNode(); node != nil; {
+			if name := node.Name(); name != "" {
+				if targets := names.TargetNames(name); targets != nil {
+					m[name] = targets
+				}
+			}
+			parent := node.Parent()
+			if parent == nil {
+				break
+			}
+			node = parent.GetNode()
+		}
+	}
+	return m
+}
+
+// TargetNames satisfies the MorphNames interface
+func (m morphNameMap) TargetNames(name string) []string {
+
+	return m[name]
+}
+
+// morphPanel is a panel with weight sliders for the morph targets of the meshes of a model.
+// The slider values either override the weights set by the running animations
+// or are added to them.
+type morphPanel struct {
+	*gui.Panel
+	meshes   []*morphMesh
+	override bool // Slider values override the animated weights
+}
+
+// morphMesh is the state and weight sliders of a mesh with morph targets
+type morphMesh struct {
+	geom     *geometry.MorphGeometry
+	sliders  []*gui.Slider
+	values   []float32 // Slider values
+	base     []float32 // Weights before the slider values were applied
+	applied  []float32 // Weights set at the last update
+	updating bool      // Sliders are being updated from the weights
+}
+
+// findMorphMeshes returns the nodes with morph geometries of the specified hierarchy
+func findMorphMeshes(inode core.INode) ([]core.INode, []*geometry.MorphGeometry) {
+
+	type geometryGetter interface {
+		IGeometry() geometry.IGeometry
+	}
+	var nodes []core.INode
+	var geoms []*geometry.MorphGeometry
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		if gg, ok := inode.(geometryGetter); ok {
+			if mg, ok := gg.IGeometry().(*geometry.MorphGeometry); ok && len(mg.Weights()) > 0 {
+				nodes = append(nodes, inode)
+				geoms = append(geoms, mg)
+			}
+		}
+		for _, child := range inode.GetNode().Children() {
+			walk(child)
+		}
+	}
+	walk(inode)
+	return nodes, geoms
+}
+
+// targetNames returns the names of the morph targets of the specified mesh from
+// the names of the mesh and its ancestors known by the loader, or generic names.
+func targetNames(names MorphNames, inode core.INode, count int) []string {
+
+	var found []string
+	if names != nil {
+		for node := inode.GetNode(); node != nil && found == nil; {
+			found = names.TargetNames(node.Name())
+			parent := node.Parent()
+			if parent == nil {
+				break
+			}
+			node = parent.GetNode()
+		}
+	}
+	result := make([]string, count)
+	for i := range result {
+		if i < len(found) && found[i] != "" {
+			result[i] = found[i]
+		} else {
+			result[i] = fmt.Sprintf("target %d", i)
+		}
+	}
+	return result
+}
+
+// newMorphPanel creates and returns the panel with the morph target sliders of the
+// specified model or nil if it has no morph targets. If override is false the slider
+// values are added to the animated weights.
+func newMorphPanel(model core.INode, names MorphNames, override bool) *morphPanel {
+
+	nodes, geoms := findMorphMeshes(model)
+	if len(nodes) == 0 {
+		return nil
+	}
+	mp := &morphPanel{override: override}
+	mp.Panel = gui.NewPanel(util.Scaled(250), 0)
+	mp.SetPaddings(2, 2, 2, 2)
+	layout := gui.NewVBoxLayout()
+	layout.SetSpacing(util.Scaled(4))
+	layout.SetAutoHeight(true)
+	mp.SetLayout(layout)
+
+	cbOverride := gui.NewCheckBox("Override animation")
+	cbOverride.SetValue(mp.override)
+	cbOverride.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		mp.setOverride(cbOverride.Value())
+	})
+	mp.Add(cbOverride)
+
+	for i, node := range nodes {
+		mm := &morphMesh{geom: geoms[i]}
+		weights := mm.geom.Weights()
+		mm.values = make([]float32, len(weights))
+		mm.base = append([]float32(nil), weights...)
+		mm.applied = append([]float32(nil), weights...)
+		if mp.override {
+			copy(mm.values, weights)
+		}
+		name := node.GetNode().Name()
+		if name == "" {
+			name = fmt.Sprintf("mesh %d", i)
+		}
+		mp.Add(gui.NewLabel(name))
+		for j, tname := range targetNames(names, node, len(weights)) {
+			idx := j
+			s := gui.NewHSlider(mp.ContentWidth(), util.Scaled(24))
+			s.SetText(tname)
+			s.SetValue(mm.values[idx])
+			s.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+				if !mm.updating {
+					mm.values[idx] = s.Value()
+				}
+			})
+			mp.Add(s)
+			mm.sliders = append(mm.sliders, s)
+		}
+		mp.meshes = append(mp.meshes, mm)
+	}
+	return mp
+}
+
+// setOverride sets if the slider values override the animated weights.
+// When overriding starts the sliders take the current weights,
+// otherwise they are reset to zero.
+func (mp *morphPanel) setOverride(override bool) {
+
+	mp.override = override
+	for _, mm := range mp.meshes {
+		for i := range mm.values {
+			if override {
+				mm.values[i] = mm.base[i]
+			} else {
+				mm.values[i] = 0
+			}
+		}
+		mm.updateSliders()
+	}
+}
+
+// update applies the slider values to the morph target weights.
+// Must be called after the animations are updated.
+func (mp *morphPanel) update() {
+
+	for _, mm := range mp.meshes {
+		weights := mm.geom.Weights()
+		// Weights changed since the last update were set by the animations
+		for i, w := range weights {
+			if w != mm.applied[i] {
+				mm.base[i] = w
+			}
+		}
+		for i := range weights {
+			if mp.override {
+				mm.applied[i] = mm.values[i]
+			} else {
+				mm.applied[i] = mm.base[i] + mm.values[i]
+			}
+		}
+		mm.geom.SetWeights(append([]float32(nil), mm.applied...))
+	}
+}
+
+// updateSliders updates the sliders from the slider values
+func (mm *morphMesh) updateSliders() {
+
+	mm.updating = true
+	for i, s := range mm.sliders {
+		s.SetValue(mm.values[i])
+	}
+	mm.updating = false
+}
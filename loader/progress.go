@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/util"
+)
+
+// loadProgress is a panel shown while a model is loaded in the background,
+// with the file name, the elapsed time, an activity bar and a cancel button.
+// The loaders do not report their progress, so the bar only shows activity.
+type loadProgress struct {
+	*gui.Panel
+	label   *gui.Label  // File name and elapsed time
+	bar     *gui.Panel  // Activity bar background
+	block   *gui.Panel  // Moving block of the activity bar
+	cancel  *gui.Button // Cancel button
+	name    string      // Base name of the file being loaded
+	elapsed float32     // Elapsed time in seconds
+}
+
+// newLoadProgress creates and returns the progress panel for the specified file.
+// The onCancel function is called when the cancel button is clicked.
+func newLoadProgress(fpath string, onCancel func()) *loadProgress {
+
+	lp := new(loadProgress)
+	lp.name = filepath.Base(fpath)
+	lp.Panel = gui.NewPanel(util.Scaled(300), 0)
+	lp.SetPaddings(6, 6, 6, 6)
+	lp.SetBorders(1, 1, 1, 1)
+	lp.SetBordersColor(&math32.Color{0.3, 0.3, 0.3})
+	lp.SetColor(&math32.Color{0.9, 0.9, 0.9})
+	layout := gui.NewVBoxLayout()
+	layout.SetSpacing(util.Scaled(6))
+	layout.SetAutoHeight(true)
+	lp.SetLayout(layout)
+
+	lp.label = gui.NewLabel("")
+	lp.Add(lp.label)
+
+	lp.bar = gui.NewPanel(lp.ContentWidth(), util.Scaled(8))
+	lp.bar.SetColor(&math32.Color{0.7, 0.7, 0.7})
+	lp.block = gui.NewPanel(lp.bar.Width()/4, lp.bar.Height())
+	lp.block.SetColor(&math32.Color{0.2, 0.5, 0.9})
+	lp.bar.Add(lp.block)
+	lp.Add(lp.bar)
+
+	lp.cancel = gui.NewButton("Cancel")
+	lp.cancel.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		onCancel()
+	})
+	lp.Add(lp.cancel)
+	lp.update(0)
+	return lp
+}
+
+// update advances the elapsed time by the specified delta in seconds
+// and updates the label and the activity bar
+func (lp *loadProgress) update(dt float32) {
+
+	lp.elapsed += dt
+	lp.label.SetText(fmt.Sprintf("Loading %s  %.1fs", lp.name, lp.elapsed))
+
+	// The block moves back and forth once every two seconds
+	phase := lp.elapsed - 2*math32.Floor(lp.elapsed/2)
+	if phase > 1 {
+		phase = 2 - phase
+	}
+	lp.block.SetPositionX(phase * (lp.bar.Width() - lp.block.Width()))
+}
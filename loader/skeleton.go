@@ -0,0 +1,245 @@
+package loader
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/util"
+)
+
+// skeletonOverlay draws the bones and joint axes of the skeletons of a model
+// over the scene, following the animated pose, and shows the joint names and
+// the transforms of a selected joint.
+type skeletonOverlay struct {
+	joints    []*core.Node   // Joints of all skeletons
+	parents   []int          // Index of the parent joint of each joint or -1
+	lines     *graphic.Lines // Bones and joint axes
+	positions math32.ArrayF32
+	colors    math32.ArrayF32
+	axisSize  float32       // Length of the joint axes
+	names     []*gui.Label  // Joint name labels
+	info      *gui.Label    // Transforms of the selected joint
+	selected  int           // Index of the selected joint or -1
+	showNames bool          // Show joint names
+	panel     *gui.Panel    // Panel for the joint labels
+	dropdown  *gui.DropDown // Joint selector
+}
+
+// findJoints returns the joints of all the skeletons of the skinned meshes of the specified hierarchy
+func findJoints(inode core.INode) []*core.Node {
+
+	type skinned interface {
+		Skeleton() *graphic.Skeleton
+	}
+	var joints []*core.Node
+	found := make(map[*core.Node]bool)
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		if sm, ok := inode.(skinned); ok && sm.Skeleton() != nil {
+			for _, bone := range sm.Skeleton().Bones() {
+				if !found[bone] {
+					found[bone] = true
+					joints = append(joints, bone)
+				}
+			}
+		}
+		for _, child := range inode.GetNode().Children() {
+			walk(child)
+		}
+	}
+	walk(inode)
+	return joints
+}
+
+// newSkeletonOverlay creates the overlay for the skeletons of the specified model and
+// adds it to the scene and the GUI. Returns nil if the model has no skeletons.
+func newSkeletonOverlay(a *app.App, model core.INode) *skeletonOverlay {
+
+	joints := findJoints(model)
+	if len(joints) == 0 {
+		return nil
+	}
+	so := &skeletonOverlay{joints: joints, selected: -1}
+
+	// Finds the parent joint of each joint
+	index := make(map[*core.Node]int)
+	for i, j := range joints {
+		index[j] = i
+	}
+	for _, j := range joints {
+		parent := -1
+		if p := j.Parent(); p != nil {
+			if pi, ok := index[p.GetNode()]; ok {
+				parent = pi
+			}
+		}
+		so.parents = append(so.parents, parent)
+	}
+
+	// Axes size relative to the model size
+	bbox := util.WorldBoundingBox(model)
+	so.axisSize = bbox.Size(nil).Length() / 40
+
+	// Creates the lines: one bone and three axes per joint
+	count := len(joints) * 4 * 2
+	so.positions = math32.NewArrayF32(count*3, count*3)
+	so.colors = math32.NewArrayF32(0, count*3)
+	boneColor := math32.Color{1, 1, 0}
+	axisColors := []math32.Color{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for range joints {
+		so.colors.Append(boneColor.R, boneColor.G, boneColor.B, boneColor.R, boneColor.G, boneColor.B)
+		for _, c := range axisColors {
+			so.colors.Append(c.R, c.G, c.B, c.R, c.G, c.B)
+		}
+	}
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(so.positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(so.colors).AddAttrib(gls.VertexColor))
+	mat := material.NewBasic()
+	mat.SetDepthTest(false)
+	so.lines = graphic.NewLines(geom, mat)
+	a.Scene().Add(so.lines)
+
+	// Creates the joint name labels and the information label
+	so.panel = gui.NewPanel(0, 0)
+	so.panel.SetEnabled(false)
+	a.GuiPanel().Add(so.panel)
+	for i, j := range joints {
+		name := j.Name()
+		if name == "" {
+			name = fmt.Sprintf("joint %d", i)
+		}
+		l := gui.NewLabel(name)
+		l.SetVisible(false)
+		so.panel.Add(l)
+		so.names = append(so.names, l)
+	}
+	so.info = gui.NewLabel("")
+	so.info.SetPaddings(4, 4, 4, 4)
+	so.info.SetBgColor4(&math32.Color4{0, 0, 0, 0.5})
+	so.info.SetColor(&math32.Color{1, 1, 1})
+	so.info.SetVisible(false)
+	a.GuiPanel().Add(so.info)
+
+	// Creates the joint selector
+	so.dropdown = gui.NewDropDown(util.Scaled(200), gui.NewImageLabel("Select joint"))
+	so.dropdown.Add(gui.NewImageLabel("none"))
+	for _, l := range so.names {
+		so.dropdown.Add(gui.NewImageLabel(l.Text()))
+	}
+	so.dropdown.SetPosition(util.Scaled(320), 10)
+	so.dropdown.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		so.selected = so.dropdown.SelectedPos() - 1
+	})
+	a.GuiPanel().Add(so.dropdown)
+	return so
+}
+
+// dispose removes the overlay from the scene and the GUI
+func (so *skeletonOverlay) dispose(a *app.App) {
+
+	a.Scene().Remove(so.lines)
+	so.lines.Dispose()
+	a.GuiPanel().Remove(so.panel)
+	so.panel.Dispose()
+	a.GuiPanel().Remove(so.info)
+	so.info.Dispose()
+	a.GuiPanel().Remove(so.dropdown)
+	so.dropdown.Dispose()
+}
+
+// setVisible shows or hides the overlay
+func (so *skeletonOverlay) setVisible(visible bool) {
+
+	so.lines.SetVisible(visible)
+	so.panel.SetVisible(visible)
+	so.dropdown.SetVisible(visible)
+	so.info.SetVisible(visible && so.selected >= 0)
+}
+
+// update updates the bones, axes and labels from the current pose of the joints
+func (so *skeletonOverlay) update(a *app.App) {
+
+	if !so.lines.Visible() {
+		return
+	}
+	type projector interface {
+		Project(v *math32.Vector3) (*math32.Vector3, error)
+	}
+	proj, canProject := a.Camera().(projector)
+	width, height := a.GuiPanel().ContentWidth(), a.GuiPanel().ContentHeight()
+
+	pos := make([]math32.Vector3, len(so.joints))
+	for i, j := range so.joints {
+		j.WorldPosition(&pos[i])
+	}
+	for i, j := range so.joints {
+		// Bone from the parent joint or a point if it has no parent joint
+		off := i * 8 * 3
+		from := pos[i]
+		if so.parents[i] >= 0 {
+			from = pos[so.parents[i]]
+		}
+		so.positions.SetVector3(off, &from)
+		so.positions.SetVector3(off+3, &pos[i])
+
+		// Joint axes in world coordinates, larger for the selected joint
+		size := so.axisSize
+		if i == so.selected {
+			size *= 3
+		}
+		mw := j.MatrixWorld()
+		for k, axis := range []math32.Vector3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}} {
+			axis.TransformDirection(&mw).MultiplyScalar(size).Add(&pos[i])
+			so.positions.SetVector3(off+6+k*6, &pos[i])
+			so.positions.SetVector3(off+9+k*6, &axis)
+		}
+
+		// Joint name label at the projected joint position
+		l := so.names[i]
+		show := so.showNames && canProject
+		if show {
+			p := pos[i]
+			ndc, err := proj.Project(&p)
+			show = err == nil && ndc.Z < 1
+			if show {
+				l.SetPosition((ndc.X+1)/2*width, (1-ndc.Y)/2*height)
+			}
+		}
+		l.SetVisible(show)
+	}
+	so.lines.GetGeometry().VBO(gls.VertexPosition).SetBuffer(so.positions)
+
+	// Transforms of the selected joint
+	if so.selected < 0 {
+		so.info.SetVisible(false)
+		return
+	}
+	j := so.joints[so.selected]
+	lp := j.Position()
+	lr := j.Rotation()
+	ls := j.Scale()
+	wp := pos[so.selected]
+	var wq math32.Quaternion
+	j.WorldQuaternion(&wq)
+	var rm math32.Matrix4
+	rm.MakeRotationFromQuaternion(&wq)
+	var wr math32.Vector3
+	wr.SetFromRotationMatrix(&rm)
+	so.info.SetText(fmt.Sprintf("%s\nlocal position: %.3f %.3f %.3f\nlocal rotation: %.1f %.1f %.1f\nlocal scale: %.3f %.3f %.3f\nworld position: %.3f %.3f %.3f\nworld rotation: %.1f %.1f %.1f",
+		so.names[so.selected].Text(),
+		lp.X, lp.Y, lp.Z,
+		math32.RadToDeg(lr.X), math32.RadToDeg(lr.Y), math32.RadToDeg(lr.Z),
+		ls.X, ls.Y, ls.Z,
+		wp.X, wp.Y, wp.Z,
+		math32.RadToDeg(wr.X), math32.RadToDeg(wr.Y), math32.RadToDeg(wr.Z)))
+	so.info.SetPosition(width-so.info.Width()-10, height-so.info.Height()-10)
+	so.info.SetVisible(true)
+}
@@ -0,0 +1,27 @@
+package loader
+
+import (
+	"path/filepath"
+
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/demos"
+)
+
+func init() {
+	demos.Map["loader.stl_ply"] = &LoaderStlPly{}
+}
+
+type LoaderStlPly struct {
+	modelViewer
+}
+
+func (t *LoaderStlPly) Initialize(a *app.App) {
+
+	t.initialize(a, a.DirData(), []string{".stl", ".ply"})
+	t.loadFile(a, filepath.Join(a.DirData(), "ply/cube_colors.ply"))
+}
+
+func (t *LoaderStlPly) Render(a *app.App) {
+
+	t.update(a)
+}
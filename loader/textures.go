@@ -0,0 +1,168 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/g3nd/util"
+)
+
+// textureResolver finds the texture images referenced by a model file in the
+// search paths and replaces the missing ones by a placeholder image
+type textureResolver struct {
+	dirs        []string        // Directories searched in order
+	warnings    []string        // Missing textures
+	missing     map[string]bool // Missing references already reported
+	tmpdir      string          // Private temporary directory of the placeholder file
+	placeholder string          // Placeholder file, created on first use
+}
+
+// newTextureResolver returns a resolver for the textures of the specified model file
+func newTextureResolver(fpath string) *textureResolver {
+
+	dir := filepath.Dir(fpath)
+	r := &textureResolver{missing: make(map[string]bool)}
+	r.dirs = append([]string{dir, filepath.Join(dir, "textures")}, util.TexturePaths()...)
+	return r
+}
+
+// resolve returns the path of the image file of the specified texture reference
+// relative to the specified directory, where the decoders look for it,
+// or the absolute path if the directory is empty.
+// If the image is not found in the search paths, a warning is added and
+// empty is returned, so the caller can use the placeholder image instead.
+func (r *textureResolver) resolve(ref, dir string) string {
+
+	fpath := r.find(ref)
+	if fpath == "" {
+		if !r.missing[ref] {
+			r.missing[ref] = true
+			r.warnings = append(r.warnings, fmt.Sprintf("texture not found, using placeholder:%s", ref))
+		}
+		return ""
+	}
+	if dir == "" {
+		if abs, err := filepath.Abs(fpath); err == nil {
+			return abs
+		}
+		return fpath
+	}
+	return relativeURI(dir, fpath)
+}
+
+// find returns the path of the image file of the specified reference or empty if not found.
+// Each directory is searched for the reference path and then for its file name only,
+// as references often keep the directories of the machine where the model was created.
+func (r *textureResolver) find(ref string) string {
+
+	ref = strings.TrimPrefix(ref, "file://")
+	names := []string{filepath.FromSlash(strings.Replace(ref, "\\", "/", -1))}
+	if unescaped, err := url.PathUnescape(names[0]); err == nil && unescaped != names[0] {
+		names = append(names, unescaped)
+	}
+	if filepath.IsAbs(names[0]) && fileExists(names[0]) {
+		return names[0]
+	}
+	for _, dir := range r.dirs {
+		for _, name := range names {
+			if fpath := filepath.Join(dir, name); fileExists(fpath) {
+				return fpath
+			}
+			if fpath := filepath.Join(dir, filepath.Base(name)); fileExists(fpath) {
+				return fpath
+			}
+		}
+	}
+	return ""
+}
+
+// fileExists returns if the specified path is an existing regular file
+func fileExists(fpath string) bool {
+
+	fi, err := os.Stat(fpath)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// placeholderImage returns the checkerboard image used in place of missing textures
+func placeholderImage() *image.RGBA {
+
+	const size, square = 64, 8
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	magenta := color.RGBA{255, 0, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.Set(x, y, magenta)
+			} else {
+				img.Set(x, y, black)
+			}
+		}
+	}
+	return img
+}
+
+// newPlaceholderTexture returns a new texture with the placeholder image
+func newPlaceholderTexture() *texture.Texture2D {
+
+	return texture.NewTexture2DFromRGBA(placeholderImage())
+}
+
+// placeholderURI returns a data URI with the PNG encoded placeholder image
+func placeholderURI() (string, error) {
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, placeholderImage()); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// placeholderFile returns the absolute path of a file with the placeholder image
+// for the decoders which only load images from files.
+// The file is created on first use in a private temporary directory which is
+// removed by cleanup.
+func (r *textureResolver) placeholderFile() (string, error) {
+
+	if r.placeholder != "" {
+		return r.placeholder, nil
+	}
+	dir, err := ioutil.TempDir("", "g3nd")
+	if err != nil {
+		return "", err
+	}
+	r.tmpdir = dir
+	fpath := filepath.Join(dir, "missing-texture.png")
+	f, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = png.Encode(f, placeholderImage())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	r.placeholder = fpath
+	return fpath, nil
+}
+
+// cleanup removes the temporary directory of the placeholder file if it was created
+func (r *textureResolver) cleanup() {
+
+	if r.tmpdir != "" {
+		os.RemoveAll(r.tmpdir)
+		r.tmpdir, r.placeholder = "", ""
+	}
+}
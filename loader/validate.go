@@ -0,0 +1,445 @@
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/g3nd/app"
+)
+
+func init() {
+	app.SetModelValidator(ValidateFile)
+}
+
+// glTF extensions supported by the engine loader
+var gltfSupportedExtensions = map[string]bool{
+	"KHR_materials_common": true,
+}
+
+// Sizes in bytes of the glTF accessor component types
+var gltfComponentSizes = map[int]int{
+	5120: 1, // BYTE
+	5121: 1, // UNSIGNED_BYTE
+	5122: 2, // SHORT
+	5123: 2, // UNSIGNED_SHORT
+	5125: 4, // UNSIGNED_INT
+	5126: 4, // FLOAT
+}
+
+// Number of components of the glTF accessor types
+var gltfTypeComponents = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// ValidateFile parses the specified glTF file and returns the problems found in it.
+// Returns an error if the file is not a glTF file or cannot be parsed.
+func ValidateFile(fpath string) ([]app.ValidationIssue, error) {
+
+	var g *gltf.GLTF
+	var err error
+	switch strings.ToLower(filepath.Ext(fpath)) {
+	case ".gltf":
+		g, err = gltf.ParseJSON(fpath)
+	case ".glb":
+		g, err = gltf.ParseBin(fpath)
+	default:
+		return nil, fmt.Errorf("not a glTF file:%s", fpath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return validateGLTF(g, filepath.Dir(fpath)), nil
+}
+
+// gltfValidator accumulates the problems found in a glTF document
+type gltfValidator struct {
+	g      *gltf.GLTF
+	dir    string // Directory of the external files
+	issues []app.ValidationIssue
+}
+
+// validateGLTF checks the references, buffer and accessor ranges, index types,
+// external files, extensions and node hierarchy of the specified parsed glTF document,
+// whose external files are relative to the specified directory
+func validateGLTF(g *gltf.GLTF, dir string) []app.ValidationIssue {
+
+	v := &gltfValidator{g: g, dir: dir}
+	v.checkExtensions()
+	v.checkBuffers()
+	v.checkBufferViews()
+	v.checkAccessors()
+	v.checkImages()
+	v.checkTextures()
+	v.checkMaterials()
+	v.checkMeshes()
+	v.checkNodes()
+	v.checkScenes()
+	v.checkSkins()
+	v.checkAnimations()
+	return v.issues
+}
+
+// errorf adds an error at the specified JSON path
+func (v *gltfValidator) errorf(path string, format string, args ...interface{}) {
+
+	v.issues = append(v.issues, app.ValidationIssue{Severity: app.SeverityError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// warnf adds a warning at the specified JSON path
+func (v *gltfValidator) warnf(path string, format string, args ...interface{}) {
+
+	v.issues = append(v.issues, app.ValidationIssue{Severity: app.SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkIndex adds an error if the specified index is out of the range of a list of the specified length.
+// Returns if the index is valid.
+func (v *gltfValidator) checkIndex(path string, idx, count int, what string) bool {
+
+	if idx < 0 || idx >= count {
+		v.errorf(path, "%s %d not found (%d defined)", what, idx, count)
+		return false
+	}
+	return true
+}
+
+// checkFile reports the problem with the specified function if the specified
+// external file uri does not exist. URIs are percent-encoded as specified by glTF.
+// Returns the file size or -1 if not found or not a file.
+func (v *gltfValidator) checkFile(path, uri string, report func(path string, format string, args ...interface{})) int64 {
+
+	if uri == "" || strings.HasPrefix(uri, "data:") {
+		return -1
+	}
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		name = uri
+	}
+	fi, err := os.Stat(filepath.Join(v.dir, filepath.FromSlash(name)))
+	if err != nil {
+		report(path, "file not found:%s", uri)
+		return -1
+	}
+	return fi.Size()
+}
+
+func (v *gltfValidator) checkExtensions() {
+
+	required := make(map[string]bool)
+	for i, ext := range v.g.ExtensionsRequired {
+		required[ext] = true
+		if !gltfSupportedExtensions[ext] {
+			v.errorf(fmt.Sprintf("$.extensionsRequired[%d]", i), "required extension not supported:%s", ext)
+		}
+	}
+	for i, ext := range v.g.ExtensionsUsed {
+		if !required[ext] && !gltfSupportedExtensions[ext] {
+			v.warnf(fmt.Sprintf("$.extensionsUsed[%d]", i), "extension not supported and ignored:%s", ext)
+		}
+	}
+}
+
+func (v *gltfValidator) checkBuffers() {
+
+	for i, b := range v.g.Buffers {
+		path := fmt.Sprintf("$.buffers[%d]", i)
+		if b.ByteLength <= 0 {
+			v.errorf(path+".byteLength", "invalid byte length:%d", b.ByteLength)
+		}
+		size := v.checkFile(path+".uri", b.Uri, v.errorf)
+		if size >= 0 && size < int64(b.ByteLength) {
+			v.errorf(path+".byteLength", "byte length %d larger than the file size %d", b.ByteLength, size)
+		}
+	}
+}
+
+func (v *gltfValidator) checkBufferViews() {
+
+	for i, bv := range v.g.BufferViews {
+		path := fmt.Sprintf("$.bufferViews[%d]", i)
+		if !v.checkIndex(path+".buffer", bv.Buffer, len(v.g.Buffers), "buffer") {
+			continue
+		}
+		if bv.ByteLength <= 0 {
+			v.errorf(path+".byteLength", "invalid byte length:%d", bv.ByteLength)
+		}
+		blen := v.g.Buffers[bv.Buffer].ByteLength
+		if bv.ByteOffset < 0 || bv.ByteOffset+bv.ByteLength > blen {
+			v.errorf(path, "range %d to %d outside of buffer %d with %d bytes", bv.ByteOffset, bv.ByteOffset+bv.ByteLength, bv.Buffer, blen)
+		}
+		if bv.ByteStride != nil && (*bv.ByteStride < 4 || *bv.ByteStride > 252 || *bv.ByteStride%4 != 0) {
+			v.errorf(path+".byteStride", "invalid byte stride:%d", *bv.ByteStride)
+		}
+	}
+}
+
+func (v *gltfValidator) checkAccessors() {
+
+	for i, acc := range v.g.Accessors {
+		path := fmt.Sprintf("$.accessors[%d]", i)
+		csize, okc := gltfComponentSizes[acc.ComponentType]
+		if !okc {
+			v.errorf(path+".componentType", "invalid component type:%d", acc.ComponentType)
+		}
+		ncomp, okt := gltfTypeComponents[acc.Type]
+		if !okt {
+			v.errorf(path+".type", "invalid type:%s", acc.Type)
+		}
+		if acc.Count <= 0 {
+			v.errorf(path+".count", "invalid count:%d", acc.Count)
+		}
+		if okt && len(acc.Max) > 0 && len(acc.Max) != ncomp {
+			v.warnf(path+".max", "has %d values for type %s", len(acc.Max), acc.Type)
+		}
+		if okt && len(acc.Min) > 0 && len(acc.Min) != ncomp {
+			v.warnf(path+".min", "has %d values for type %s", len(acc.Min), acc.Type)
+		}
+		if acc.BufferView == nil {
+			continue
+		}
+		if !v.checkIndex(path+".bufferView", *acc.BufferView, len(v.g.BufferViews), "buffer view") || !okc || !okt || acc.Count <= 0 {
+			continue
+		}
+
+		// Checks that all the elements are inside the buffer view
+		bv := v.g.BufferViews[*acc.BufferView]
+		elemSize := csize * ncomp
+		stride := elemSize
+		if bv.ByteStride != nil && *bv.ByteStride > 0 {
+			stride = *bv.ByteStride
+		}
+		end := acc.ByteOffset + stride*(acc.Count-1) + elemSize
+		if acc.ByteOffset < 0 || end > bv.ByteLength {
+			v.errorf(path, "%d elements of %d bytes from offset %d need %d bytes but buffer view %d has %d",
+				acc.Count, elemSize, acc.ByteOffset, end, *acc.BufferView, bv.ByteLength)
+		}
+		if acc.ByteOffset%csize != 0 {
+			v.errorf(path+".byteOffset", "offset %d not aligned to the component size %d", acc.ByteOffset, csize)
+		}
+	}
+}
+
+func (v *gltfValidator) checkImages() {
+
+	for i, img := range v.g.Images {
+		path := fmt.Sprintf("$.images[%d]", i)
+		if img.BufferView != nil {
+			v.checkIndex(path+".bufferView", *img.BufferView, len(v.g.BufferViews), "buffer view")
+			if img.MimeType == "" {
+				v.errorf(path+".mimeType", "required for images in buffer views")
+			}
+			continue
+		}
+		if img.Uri == "" {
+			v.errorf(path, "no uri or buffer view")
+			continue
+		}
+		// Missing images are replaced by a placeholder texture when loading
+		v.checkFile(path+".uri", img.Uri, v.warnf)
+	}
+}
+
+func (v *gltfValidator) checkTextures() {
+
+	for i, tex := range v.g.Textures {
+		path := fmt.Sprintf("$.textures[%d]", i)
+		if tex.Source == nil {
+			v.warnf(path+".source", "texture without image")
+		} else {
+			v.checkIndex(path+".source", *tex.Source, len(v.g.Images), "image")
+		}
+		if tex.Sampler != nil {
+			v.checkIndex(path+".sampler", *tex.Sampler, len(v.g.Samplers), "sampler")
+		}
+	}
+}
+
+func (v *gltfValidator) checkMaterials() {
+
+	ntex := len(v.g.Textures)
+	for i, m := range v.g.Materials {
+		path := fmt.Sprintf("$.materials[%d]", i)
+		if pbr := m.PbrMetallicRoughness; pbr != nil {
+			if pbr.BaseColorTexture != nil {
+				v.checkIndex(path+".pbrMetallicRoughness.baseColorTexture.index", pbr.BaseColorTexture.Index, ntex, "texture")
+			}
+			if pbr.MetallicRoughnessTexture != nil {
+				v.checkIndex(path+".pbrMetallicRoughness.metallicRoughnessTexture.index", pbr.MetallicRoughnessTexture.Index, ntex, "texture")
+			}
+		}
+		if m.NormalTexture != nil {
+			v.checkIndex(path+".normalTexture.index", m.NormalTexture.Index, ntex, "texture")
+		}
+		if m.OcclusionTexture != nil {
+			v.checkIndex(path+".occlusionTexture.index", m.OcclusionTexture.Index, ntex, "texture")
+		}
+		if m.EmissiveTexture != nil {
+			v.checkIndex(path+".emissiveTexture.index", m.EmissiveTexture.Index, ntex, "texture")
+		}
+	}
+}
+
+func (v *gltfValidator) checkMeshes() {
+
+	nacc := len(v.g.Accessors)
+	for i, mesh := range v.g.Meshes {
+		for j, prim := range mesh.Primitives {
+			path := fmt.Sprintf("$.meshes[%d].primitives[%d]", i, j)
+
+			// Vertex attributes, which must have the same number of elements
+			vertices := -1
+			pos, hasPos := prim.Attributes["POSITION"]
+			if !hasPos {
+				v.errorf(path+".attributes", "missing POSITION attribute")
+			} else if v.checkIndex(path+".attributes.POSITION", pos, nacc, "accessor") {
+				vertices = v.g.Accessors[pos].Count
+			}
+			for name, idx := range prim.Attributes {
+				apath := path + ".attributes." + name
+				if name == "POSITION" || !v.checkIndex(apath, idx, nacc, "accessor") {
+					continue
+				}
+				if vertices >= 0 && v.g.Accessors[idx].Count != vertices {
+					v.errorf(apath, "has %d elements but POSITION has %d", v.g.Accessors[idx].Count, vertices)
+				}
+			}
+
+			// Indices must be unsigned scalars inside the vertex range
+			if prim.Indices != nil && v.checkIndex(path+".indices", *prim.Indices, nacc, "accessor") {
+				acc := v.g.Accessors[*prim.Indices]
+				switch acc.ComponentType {
+				case 5121, 5123, 5125:
+				default:
+					v.errorf(path+".indices", "invalid index component type %d: must be UNSIGNED_BYTE, UNSIGNED_SHORT or UNSIGNED_INT", acc.ComponentType)
+				}
+				if acc.Type != "SCALAR" {
+					v.errorf(path+".indices", "invalid index type %s: must be SCALAR", acc.Type)
+				}
+				if vertices >= 0 && len(acc.Max) > 0 && int(acc.Max[0]) >= vertices {
+					v.errorf(path+".indices", "maximum index %d out of the %d vertices", int(acc.Max[0]), vertices)
+				}
+			}
+			if prim.Material != nil {
+				v.checkIndex(path+".material", *prim.Material, len(v.g.Materials), "material")
+			}
+			for k, target := range prim.Targets {
+				for name, idx := range target {
+					v.checkIndex(fmt.Sprintf("%s.targets[%d].%s", path, k, name), idx, nacc, "accessor")
+				}
+			}
+		}
+	}
+}
+
+// checkNodes checks the node references and that the node hierarchy has no cycles
+// and no nodes with more than one parent
+func (v *gltfValidator) checkNodes() {
+
+	nnodes := len(v.g.Nodes)
+	parents := make([]int, nnodes)
+	for i := range parents {
+		parents[i] = -1
+	}
+	for i, node := range v.g.Nodes {
+		path := fmt.Sprintf("$.nodes[%d]", i)
+		for j, child := range node.Children {
+			cpath := fmt.Sprintf("%s.children[%d]", path, j)
+			if !v.checkIndex(cpath, child, nnodes, "node") {
+				continue
+			}
+			if child == i {
+				v.errorf(cpath, "node is its own child")
+				continue
+			}
+			if parents[child] >= 0 {
+				v.errorf(cpath, "node %d already is a child of node %d", child, parents[child])
+				continue
+			}
+			parents[child] = i
+		}
+		if node.Mesh != nil {
+			v.checkIndex(path+".mesh", *node.Mesh, len(v.g.Meshes), "mesh")
+		}
+		if node.Skin != nil {
+			v.checkIndex(path+".skin", *node.Skin, len(v.g.Skins), "skin")
+		}
+		if node.Camera != nil {
+			v.checkIndex(path+".camera", *node.Camera, len(v.g.Cameras), "camera")
+		}
+	}
+
+	// With a single parent per node, a cycle is found by following the parents
+	reported := make(map[int]bool)
+	for i := range v.g.Nodes {
+		visited := make(map[int]bool)
+		for n := i; n >= 0; n = parents[n] {
+			if visited[n] {
+				if !reported[n] {
+					reported[n] = true
+					v.errorf(fmt.Sprintf("$.nodes[%d].children", n), "node hierarchy has a cycle")
+				}
+				break
+			}
+			visited[n] = true
+		}
+	}
+}
+
+func (v *gltfValidator) checkScenes() {
+
+	if v.g.Scene != nil {
+		v.checkIndex("$.scene", *v.g.Scene, len(v.g.Scenes), "scene")
+	}
+	if len(v.g.Scenes) == 0 {
+		v.errorf("$.scenes", "no scenes defined")
+	}
+	for i, scene := range v.g.Scenes {
+		for j, node := range scene.Nodes {
+			v.checkIndex(fmt.Sprintf("$.scenes[%d].nodes[%d]", i, j), node, len(v.g.Nodes), "node")
+		}
+	}
+}
+
+func (v *gltfValidator) checkSkins() {
+
+	for i, skin := range v.g.Skins {
+		path := fmt.Sprintf("$.skins[%d]", i)
+		for j, joint := range skin.Joints {
+			v.checkIndex(fmt.Sprintf("%s.joints[%d]", path, j), joint, len(v.g.Nodes), "node")
+		}
+		if skin.InverseBindMatrices != nil && v.checkIndex(path+".inverseBindMatrices", *skin.InverseBindMatrices, len(v.g.Accessors), "accessor") {
+			acc := v.g.Accessors[*skin.InverseBindMatrices]
+			if acc.Count < len(skin.Joints) {
+				v.errorf(path+".inverseBindMatrices", "has %d matrices for %d joints", acc.Count, len(skin.Joints))
+			}
+		}
+	}
+}
+
+func (v *gltfValidator) checkAnimations() {
+
+	for i, anim := range v.g.Animations {
+		path := fmt.Sprintf("$.animations[%d]", i)
+		for j, sampler := range anim.Samplers {
+			spath := fmt.Sprintf("%s.samplers[%d]", path, j)
+			v.checkIndex(spath+".input", sampler.Input, len(v.g.Accessors), "accessor")
+			v.checkIndex(spath+".output", sampler.Output, len(v.g.Accessors), "accessor")
+		}
+		for j, ch := range anim.Channels {
+			cpath := fmt.Sprintf("%s.channels[%d]", path, j)
+			v.checkIndex(cpath+".sampler", ch.Sampler, len(anim.Samplers), "sampler")
+			if ch.Target.Node != nil {
+				v.checkIndex(cpath+".target.node", *ch.Target.Node, len(v.g.Nodes), "node")
+			}
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/demos"
+	"github.com/g3n/g3nd/util"
+)
+
+func init() {
+	demos.Map["loader.viewer"] = &Viewer{}
+}
+
+// Viewer is the demo which loads models of all registered formats
+type Viewer struct {
+	modelViewer
+}
+
+func (t *Viewer) Initialize(a *app.App) {
+
+	t.initialize(a, a.DirData(), util.ModelExtensions())
+	t.loadFile(a, filepath.Join(a.DirData(), "gltf/DamagedHelmet/glTF/DamagedHelmet.gltf"))
+}
+
+func (t *Viewer) Render(a *app.App) {
+
+	t.update(a)
+}
+
+// modelViewer contains the state shared by the loader demos:
+// a file selection button, default lights and the loaded model and animations.
+type modelViewer struct {
+	selFile *util.FileSelectButton
+	model   core.INode
+	anims   []util.ModelAnimation
+	stats   *gui.List    // Model statistics panel
+	player  *clipPlayer  // Animation clip player panel
+	morph   *morphPanel  // Morph target weights panel
+	loading *loadRequest // Load in progress or nil
+
+	skeleton      *skeletonOverlay // Skeleton overlay of skinned models
+	showSkeleton  bool             // Show the skeleton overlay
+	showJointName bool             // Show the joint names in the skeleton overlay
+}
+
+// initialize creates the file selection button for the specified directory and
+// file extensions, the default lights and the controls to frame the model
+func (v *modelViewer) initialize(a *app.App, dir string, exts []string) {
+
+	// Creates file selection button
+	v.selFile = util.NewFileSelectButton(dir, "Select File", 400, 300)
+	v.selFile.SetPosition(10, 10)
+	v.selFile.FS.SetFileFilters(fileFilters(exts)...)
+	a.GuiPanel().Add(v.selFile)
+	v.selFile.Subscribe("OnSelect", func(evname string, ev interface{}) {
+		v.loadFile(a, ev.(string))
+	})
+
+	// Adds white directional front light
+	l1 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	l1.SetPosition(0, 0, 10)
+	a.Scene().Add(l1)
+
+	// Adds white directional top light
+	l2 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	l2.SetPosition(0, 10, 0)
+	a.Scene().Add(l2)
+
+	// Adds white directional right light
+	l3 := light.NewDirectional(&math32.Color{1, 1, 1}, 1.0)
+	l3.SetPosition(10, 0, 0)
+	a.Scene().Add(l3)
+
+	// Adds axis helper
+	axis := graphic.NewAxisHelper(2)
+	a.Scene().Add(axis)
+
+	// Adds controls to frame the loaded model
+	addFrameControls(a, func() core.INode { return v.model })
+
+	// Adds skeleton overlay controls
+	v.showSkeleton = false
+	v.showJointName = false
+	if a.ControlFolder() != nil {
+		cbSkel := a.ControlFolder().AddCheckBox("Skeleton").SetValue(v.showSkeleton)
+		cbSkel.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			v.showSkeleton = cbSkel.Value()
+			if v.skeleton != nil {
+				v.skeleton.setVisible(v.showSkeleton)
+			}
+		})
+		cbNames := a.ControlFolder().AddCheckBox("Joint names").SetValue(v.showJointName)
+		cbNames.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			v.showJointName = cbNames.Value()
+			if v.skeleton != nil {
+				v.skeleton.showNames = v.showJointName
+			}
+		})
+	}
+
+	// Adds model statistics panel
+	v.stats = gui.NewList(util.Scaled(300), util.Scaled(300))
+	v.stats.SetPosition(10, util.Scaled(70))
+	a.GuiPanel().Add(v.stats)
+	if a.ControlFolder() != nil {
+		cb := a.ControlFolder().AddCheckBox("Model statistics").SetValue(true)
+		cb.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			v.stats.SetVisible(cb.Value())
+		})
+	}
+
+	// A load in progress was cancelled when the previous demo was torn down
+	v.loading = nil
+}
+
+// loadRequest is a model file being loaded in the background
+type loadRequest struct {
+	fpath    string        // Model file path
+	progress *loadProgress // Progress panel
+	future   *app.Future   // Result of the background load
+}
+
+// loadResult is the result of loading a model file
+type loadResult struct {
+	model    core.INode            // Loaded model or nil on error
+	anims    []util.ModelAnimation // Animations of the model
+	warnings []string              // Warnings reported by the loader
+	issues   []app.ValidationIssue // Validation problems reported by the loader
+	names    morphNameMap          // Morph target names of the meshes of the model
+	err      error                 // Loading error
+}
+
+// loadModel loads the specified model file using the loader registered for its extension.
+// It can be called from any goroutine: the loaders only parse the files, decode the images
+// and build the nodes, and the OpenGL objects are created when the model is first rendered.
+// Loading stops as soon as possible when the specified context is cancelled.
+func loadModel(ctx context.Context, fpath string) *loadResult {
+
+	res := new(loadResult)
+	l, err := util.FindModelLoader(fpath)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	if ctx.Err() != nil {
+		res.err = ctx.Err()
+		return res
+	}
+	if cl, ok := l.(ContextLoader); ok {
+		res.model, res.anims, res.err = cl.LoadContext(ctx, fpath)
+	} else {
+		res.model, res.anims, res.err = l.Load(fpath)
+	}
+	if ctx.Err() != nil {
+		// The result of a cancelled load is discarded
+		res.err = ctx.Err()
+		return res
+	}
+	if val, ok := l.(Validation); ok {
+		res.issues = val.Issues()
+	}
+	if res.err != nil {
+		return res
+	}
+	if d, ok := l.(Diagnostics); ok {
+		res.warnings = append(res.warnings, d.Warnings()...)
+	}
+	names, _ := l.(MorphNames)
+	res.names = newMorphNameMap(names, res.model)
+	return res
+}
+
+// loadFile starts loading the specified model file in the background, showing the
+// progress panel. When loading finishes the model replaces the current model and the
+// result is shown in the file selection button. A load in progress is cancelled.
+func (v *modelViewer) loadFile(a *app.App, fpath string) {
+
+	v.cancelLoad(a)
+	req := &loadRequest{fpath: fpath}
+	req.progress = newLoadProgress(fpath, func() { v.cancelLoad(a) })
+	req.progress.SetPosition((a.GuiPanel().ContentWidth()-req.progress.Width())/2, a.GuiPanel().ContentHeight()/2)
+	a.GuiPanel().Add(req.progress)
+	v.loading = req
+	req.future = a.Async(func(ctx context.Context) (interface{}, error) {
+		return loadModel(ctx, fpath), nil
+	})
+	req.future.Then(func(result interface{}, err error) {
+		v.finishLoad(a, req, result.(*loadResult))
+	})
+}
+
+// cancelLoad cancels the load in progress, if any.
+// The model will be discarded when its loading finishes.
+func (v *modelViewer) cancelLoad(a *app.App) {
+
+	if v.loading == nil {
+		return
+	}
+	v.loading.future.Cancel()
+	a.GuiPanel().Remove(v.loading.progress)
+	v.loading.progress.Dispose()
+	v.loading = nil
+}
+
+// finishLoad is called in the main thread when the specified load request finishes
+func (v *modelViewer) finishLoad(a *app.App, req *loadRequest, res *loadResult) {
+
+	a.GuiPanel().Remove(req.progress)
+	req.progress.Dispose()
+	v.loading = nil
+
+	v.setModel(a, res)
+	v.updateStats(res.warnings, res.issues)
+	if res.err == nil {
+		v.selFile.Label.SetText("File: " + filepath.Base(req.fpath))
+		v.selFile.SetError("")
+	} else {
+		v.selFile.Label.SetText("Select File")
+		v.selFile.SetError(res.err.Error())
+	}
+}
+
+// setModel replaces the current model with the specified loaded model
+// and frames the camera on it
+func (v *modelViewer) setModel(a *app.App, res *loadResult) {
+
+	// Remove previous model from the scene
+	if v.model != nil {
+		a.Scene().Remove(v.model)
+		v.model.Dispose()
+		v.model = nil
+		v.anims = nil
+	}
+	if v.player != nil {
+		a.GuiPanel().Remove(v.player)
+		v.player.Dispose()
+		v.player = nil
+	}
+	if v.skeleton != nil {
+		v.skeleton.dispose(a)
+		v.skeleton = nil
+	}
+	if v.morph != nil {
+		a.GuiPanel().Remove(v.morph)
+		v.morph.Dispose()
+		v.morph = nil
+	}
+	if res.err != nil {
+		return
+	}
+	model, anims := res.model, res.anims
+	a.Scene().Add(model)
+	v.model = model
+	v.anims = anims
+
+	// Adds the clip player if the model has animations
+	if len(anims) > 0 {
+		v.player = newClipPlayer(anims)
+		v.player.SetPosition(10, util.Scaled(380))
+		a.GuiPanel().Add(v.player)
+	}
+
+	// Adds the morph target sliders if the model has morph targets.
+	// The sliders are added to the animated weights if the model is animated.
+	v.morph = newMorphPanel(model, res.names, len(anims) == 0)
+	if v.morph != nil {
+		v.morph.SetPosition(util.Scaled(320), util.Scaled(70))
+		a.GuiPanel().Add(v.morph)
+	}
+
+	// Adds the skeleton overlay if the model is skinned
+	v.skeleton = newSkeletonOverlay(a, model)
+	if v.skeleton != nil {
+		v.skeleton.showNames = v.showJointName
+		v.skeleton.setVisible(v.showSkeleton)
+	}
+	util.FrameNode(a.Camera(), model)
+}
+
+// updateStats shows the statistics and diagnostics of the loaded model
+// with the specified warnings and validation problems reported by its loader.
+// The validation problems are also shown if the model could not be loaded.
+func (v *modelViewer) updateStats(warnings []string, issues []app.ValidationIssue) {
+
+	v.stats.Clear()
+	v.addIssues(issues)
+	if v.model == nil {
+		return
+	}
+	ms := util.NewModelStats(v.model)
+
+	ms.Warnings = append(ms.Warnings, warnings...)
+	lines := ms.Lines()
+
+	// Animations
+	lines = append(lines, fmt.Sprintf("Animations: %d", len(v.anims)))
+	for i, anim := range v.anims {
+		line := fmt.Sprintf("  #%d", i)
+		if n, ok := anim.(NamedAnimation); ok {
+			line = "  " + n.Name()
+		}
+		if d, ok := anim.(TimedAnimation); ok && d.Duration() > 0 {
+			line += fmt.Sprintf(" (%.2fs)", d.Duration())
+		}
+		lines = append(lines, line)
+	}
+
+	for _, line := range lines {
+		v.stats.Add(gui.NewLabel(line))
+	}
+}
+
+// addIssues adds the specified validation problems to the statistics panel,
+// errors in red and warnings in orange, with the JSON path of each problem
+func (v *modelViewer) addIssues(issues []app.ValidationIssue) {
+
+	if len(issues) == 0 {
+		return
+	}
+	errors := 0
+	for _, issue := range issues {
+		if issue.Severity == app.SeverityError {
+			errors++
+		}
+	}
+	v.stats.Add(gui.NewLabel(fmt.Sprintf("Validation: %d error(s), %d warning(s)", errors, len(issues)-errors)))
+	for _, issue := range issues {
+		l := gui.NewLabel(fmt.Sprintf("  %s %s: %s", issue.Severity, issue.Path, issue.Message))
+		if issue.Severity == app.SeverityError {
+			l.SetColor(&math32.Color{0.8, 0, 0})
+		} else {
+			l.SetColor(&math32.Color{0.8, 0.4, 0})
+		}
+		v.stats.Add(l)
+	}
+}
+
+// update updates the progress panel of the load in progress, the animations of the
+// loaded model with the clip player, the morph target weights and the skeleton overlay
+func (v *modelViewer) update(a *app.App) {
+
+	if v.loading != nil {
+		v.loading.progress.update(a.FrameDeltaSeconds())
+	}
+	if v.player != nil {
+		v.player.update(a.FrameDeltaSeconds())
+	}
+	if v.morph != nil {
+		v.morph.update()
+	}
+	if v.skeleton != nil {
+		v.skeleton.update(a)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	_ "github.com/g3n/g3nd/material"
 	_ "github.com/g3n/g3nd/other"
 	_ "github.com/g3n/g3nd/experimental/physics"
+	_ "github.com/g3n/g3nd/scene"
 	_ "github.com/g3n/g3nd/shader"
 	_ "github.com/g3n/g3nd/tests"
 	_ "github.com/g3n/g3nd/texture"
@@ -22,7 +23,7 @@ import (
 func main() {
 
 	// Creates application and panics if error
-	a := app.Create(demos.Map)
+	a := app.Create(demos.Map, demos.Info)
 
 	if a != nil {
 		err := a.Run()
@@ -49,10 +49,12 @@ func (t *Blending) Initialize(a *app.App) {
 	}
 	texlist := []*texture.Texture2D{}
 	for _, tname := range texnames {
-		tex, err := texture.NewTexture2DFromImage(a.DirData() + "/images/" + tname)
+		tex, err := a.Texture(a.DirData()+"/images/"+tname, nil)
 		if err != nil {
 			a.Log().Fatal("Error loading texture: %s", err)
 		}
+		// Each material which uses the texture takes its own reference
+		defer tex.Dispose()
 		texlist = append(texlist, tex)
 	}
 
@@ -78,7 +80,7 @@ func (t *Blending) Initialize(a *app.App) {
 			material := material.NewPhong(&math32.Color{1, 1, 1})
 			material.SetOpacity(1)
 			material.SetTransparent(true)
-			material.AddTexture(tex)
+			material.AddTexture(tex.Incref())
 			material.SetBlending(blendings[i].value)
 			x := (float32(i) - float32(len(blendings))/2) * 110
 			mesh := graphic.NewMesh(geo1.Incref(), material)
@@ -6,7 +6,6 @@ import (
 	"github.com/g3n/engine/light"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
-	"github.com/g3n/engine/texture"
 	"github.com/g3n/g3nd/app"
 	"github.com/g3n/g3nd/demos"
 )
@@ -31,27 +30,27 @@ func (t *Boxmulti2) Initialize(a *app.App) {
 	a.Scene().Add(axis)
 
 	// Creates textures
-	tex0, err := texture.NewTexture2DFromImage(a.DirData() + "/images/checkerboard.jpg")
+	tex0, err := a.Texture(a.DirData()+"/images/checkerboard.jpg", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex1, err := texture.NewTexture2DFromImage(a.DirData() + "/images/brick1.jpg")
+	tex1, err := a.Texture(a.DirData()+"/images/brick1.jpg", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex2, err := texture.NewTexture2DFromImage(a.DirData() + "/images/wall1.jpg")
+	tex2, err := a.Texture(a.DirData()+"/images/wall1.jpg", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex3, err := texture.NewTexture2DFromImage(a.DirData() + "/images/uvgrid.jpg")
+	tex3, err := a.Texture(a.DirData()+"/images/uvgrid.jpg", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex4, err := texture.NewTexture2DFromImage(a.DirData() + "/images/moss.png")
+	tex4, err := a.Texture(a.DirData()+"/images/moss.png", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex5, err := texture.NewTexture2DFromImage(a.DirData() + "/images/tiger1.jpg")
+	tex5, err := a.Texture(a.DirData()+"/images/tiger1.jpg", nil)
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
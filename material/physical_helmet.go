@@ -4,7 +4,6 @@ import (
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/g3nd/app"
 	"github.com/g3n/g3nd/demos"
-	"github.com/g3n/engine/loader/obj"
 	"github.com/g3n/engine/graphic"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/texture"
@@ -60,7 +59,7 @@ func (t *PhysicalHelmet) Initialize(a *app.App) {
 	// DamagedHelmet
 
 	// Decodes obj file and associated mtl file
-	dec, err := obj.Decode(a.DirData()+"/obj/DamagedHelmet.obj", "")
+	dec, err := a.OBJModel(a.DirData()+"/obj/DamagedHelmet.obj", "")
 	if err != nil {
 		panic(err)
 	}
@@ -73,12 +72,10 @@ func (t *PhysicalHelmet) Initialize(a *app.App) {
 
 	// Helper function to load texture and handle errors
 	newTexture := func(path string) *texture.Texture2D {
-		tex, err := texture.NewTexture2DFromImage(path)
+		tex, err := a.Texture(path, &app.TextureOptions{WrapS: gls.MIRRORED_REPEAT, WrapT: gls.MIRRORED_REPEAT})
 		if err != nil {
 			a.Log().Fatal("Error loading texture: %s", err)
 		}
-		tex.SetWrapS(gls.MIRRORED_REPEAT)
-		tex.SetWrapT(gls.MIRRORED_REPEAT)
 		return tex
 	}
 
@@ -15,8 +15,8 @@ type Skybox struct {
 
 func (t *Skybox) Initialize(a *app.App) {
 
-	// Create Skybox
-	skybox, err := graphic.NewSkybox(graphic.SkyboxData{
+	// Create Skybox decoding its images concurrently
+	skybox, err := a.Skybox(graphic.SkyboxData{
 		a.DirData() + "/images/sanfrancisco/", "jpg",
 		[6]string{"posx", "negx", "posy", "negy", "posz", "negz"}})
 	if err != nil {
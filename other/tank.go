@@ -8,7 +8,6 @@ import (
 	"github.com/g3n/engine/light"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
-	"github.com/g3n/engine/texture"
 	"github.com/g3n/engine/window"
 	"github.com/g3n/g3nd/app"
 	"github.com/g3n/g3nd/demos"
@@ -223,7 +222,7 @@ func (t *TankTest) newTankModel() *TankModel {
 
 	// Loads tank wheel texture
 	texfile := t.a.DirData() + "/images/wheel.png"
-	tex, err := texture.NewTexture2DFromImage(texfile)
+	tex, err := t.a.Texture(texfile, nil)
 	if err != nil {
 		t.a.Log().Fatal("Error:%s loading texture:%s", err, texfile)
 	}
@@ -0,0 +1,130 @@
+package scene
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/g3nd/app"
+	"github.com/g3n/g3nd/demos"
+	"github.com/g3n/g3nd/util"
+)
+
+func init() {
+	demos.Map["scene.file"] = &SceneFile{}
+}
+
+type SceneFile struct {
+	selFile   *util.FileSelectButton
+	instance  *util.SceneInstance // Currently built scene
+	fpath     string              // Path of the current scene file
+	modTime   time.Time           // Modification time of the current scene file
+	hotReload bool                // Reloads the scene file when it changes
+	elapsed   float32             // Time since last modification check
+}
+
+func (t *SceneFile) Initialize(a *app.App) {
+
+	// Creates file selection button
+	t.selFile = util.NewFileSelectButton(a.DirData()+"/scene", "Select File", 400, 300)
+	t.selFile.SetPosition(10, 10)
+	t.selFile.FS.SetFileFilters("*.yaml", "*.yml", "*.json")
+	a.GuiPanel().Add(t.selFile)
+	t.selFile.Subscribe("OnSelect", func(evname string, ev interface{}) {
+		t.load(a, ev.(string))
+	})
+
+	// Add controls
+	t.hotReload = true
+	if a.ControlFolder() != nil {
+		g1 := a.ControlFolder().AddGroup("Scene file")
+		cb1 := g1.AddCheckBox("Hot reload").SetValue(true)
+		cb1.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+			t.hotReload = cb1.Value()
+		})
+		b1 := gui.NewButton("Reload")
+		b1.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			t.load(a, t.fpath)
+		})
+		g1.AddPanel(b1)
+	}
+
+	// Loads default scene file
+	t.load(a, filepath.Join(a.DirData(), "scene/shapes.yaml"))
+}
+
+// load decodes and builds the specified scene file replacing the current one
+func (t *SceneFile) load(a *app.App, fpath string) {
+
+	// Saves the file path and modification time even if the file is invalid
+	// so it is reloaded when fixed.
+	t.fpath = fpath
+	if fi, err := os.Stat(fpath); err == nil {
+		t.modTime = fi.ModTime()
+	}
+
+	sf, err := util.DecodeSceneFile(fpath)
+	if err != nil {
+		t.setError(a, err)
+		return
+	}
+	inst, err := sf.Build()
+	if err != nil {
+		t.setError(a, err)
+		return
+	}
+
+	// Remove previous scene
+	if t.instance != nil {
+		a.Scene().Remove(t.instance.Root)
+		t.instance.Root.Dispose()
+	}
+	t.instance = inst
+	a.Scene().Add(inst.Root)
+
+	// Sets scene parameters
+	if len(sf.Background) == 3 {
+		a.Gl().ClearColor(sf.Background[0], sf.Background[1], sf.Background[2], 1.0)
+	} else {
+		a.Gl().ClearColor(0.6, 0.6, 0.6, 1.0)
+	}
+	if sf.Ambient != nil {
+		a.AmbLight().SetIntensity(*sf.Ambient)
+	}
+	sf.ApplyCamera(a.Camera())
+
+	t.selFile.Label.SetText("File: " + filepath.Base(fpath))
+	t.selFile.SetError("")
+	a.Log().Info("Loaded scene file:%s", fpath)
+}
+
+// setError shows an error loading the current scene file keeping the previous scene
+func (t *SceneFile) setError(a *app.App, err error) {
+
+	t.selFile.Label.SetText("File: " + filepath.Base(t.fpath))
+	t.selFile.SetError(err.Error())
+	a.Log().Error("%v", err)
+}
+
+func (t *SceneFile) Render(a *app.App) {
+
+	if t.instance != nil {
+		t.instance.Update(a.FrameDeltaSeconds())
+	}
+
+	// Checks once per second if the scene file was modified
+	if !t.hotReload || t.fpath == "" {
+		return
+	}
+	t.elapsed += a.FrameDeltaSeconds()
+	if t.elapsed < 1 {
+		return
+	}
+	t.elapsed = 0
+	fi, err := os.Stat(t.fpath)
+	if err != nil || !fi.ModTime().After(t.modTime) {
+		return
+	}
+	t.load(a, t.fpath)
+}
@@ -25,8 +25,8 @@ func (t *Earth) Initialize(a *app.App) {
 	t.a = a
 	a.AmbLight().SetIntensity(1)
 
-	// Create Skybox
-	skybox, err := graphic.NewSkybox(graphic.SkyboxData{
+	// Create Skybox decoding its images concurrently
+	skybox, err := a.Skybox(graphic.SkyboxData{
 		a.DirData() + "/images/space/dark-s_", "jpg",
 		[6]string{"px", "nx", "py", "ny", "pz", "nz"}})
 	if err != nil {
@@ -44,21 +44,17 @@ func (t *Earth) Initialize(a *app.App) {
 	t.a.Renderer().AddShader("shaderEarthFrag", shaderEarthFrag)
 	t.a.Renderer().AddProgram("shaderEarth", "shaderEarthVertex", "shaderEarthFrag")
 
-	// Helper function to load a texture and handle errors
-	newTexture := func(path string) *texture.Texture2D {
-		tex, err := texture.NewTexture2DFromImage(path)
-		if err != nil {
-			a.Log().Fatal("Error loading texture: %s", err)
-		}
-		tex.SetFlipY(false)
-		return tex
+	// Create earth textures decoding the images concurrently
+	texs, err := a.Textures([]string{
+		a.DirData() + "/images/earth_clouds_big.jpg",
+		a.DirData() + "/images/earth_spec_big.jpg",
+		a.DirData() + "/images/earth_night_big.jpg",
+		//a.DirData() + "/images/earth_bump_big.jpg",
+	}, &app.TextureOptions{NoFlipY: true})
+	if err != nil {
+		a.Log().Fatal("Error loading texture: %s", err)
 	}
-
-	// Create earth textures
-	texDay := newTexture(a.DirData() + "/images/earth_clouds_big.jpg")
-	texSpecular := newTexture(a.DirData() + "/images/earth_spec_big.jpg")
-	texNight := newTexture(a.DirData() + "/images/earth_night_big.jpg")
-	//texBump, err := newTexture(a.DirData() + "/images/earth_bump_big.jpg")
+	texDay, texSpecular, texNight := texs[0], texs[1], texs[2]
 
 	// Create custom material using the custom shader
 	matEarth := NewEarthMaterial(&math32.Color{1, 1, 1})
@@ -42,7 +42,7 @@ func (t *Texplane) Initialize(a *app.App) {
 
 	// Loads texture from image
 	texfile := a.DirData() + "/images/uvgrid.jpg"
-	tex1, err := texture.NewTexture2DFromImage(texfile)
+	tex1, err := a.Texture(texfile, nil)
 	if err != nil {
 		a.Log().Fatal("Error:%s loading texture:%s", err, texfile)
 	}
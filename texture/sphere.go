@@ -38,13 +38,10 @@ func (t *Texsphere) Initialize(a *app.App) {
 
 	// Creates texture 1
 	texfile := a.DirData() + "/images/checkerboard.jpg"
-	tex1, err := texture.NewTexture2DFromImage(texfile)
+	tex1, err := a.Texture(texfile, &app.TextureOptions{WrapS: gls.REPEAT, WrapT: gls.REPEAT, Repeat: math32.Vector2{2, 2}})
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex1.SetWrapS(gls.REPEAT)
-	tex1.SetWrapT(gls.REPEAT)
-	tex1.SetRepeat(2, 2)
 	// Creates sphere 1
 	geom1 := geometry.NewSphere(1, 32, 32, 0, math.Pi*2, 0, math.Pi)
 	mat1 := material.NewStandard(&math32.Color{1, 1, 1})
@@ -55,11 +52,10 @@ func (t *Texsphere) Initialize(a *app.App) {
 
 	// Creates texture 2
 	texfile = a.DirData() + "/images/earth_clouds_big.jpg"
-	tex2, err := texture.NewTexture2DFromImage(texfile)
+	tex2, err := a.Texture(texfile, &app.TextureOptions{NoFlipY: true})
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex2.SetFlipY(false)
 	// Creates sphere 2
 	geom2 := geometry.NewSphere(1, 32, 32, 0, math.Pi*2, 0, math.Pi)
 	mat2 := material.NewPhong(&math32.Color{1, 1, 1})
@@ -70,11 +66,10 @@ func (t *Texsphere) Initialize(a *app.App) {
 
 	// Creates texture 3
 	texfile = a.DirData() + "/images/uvgrid.jpg"
-	tex3, err := texture.NewTexture2DFromImage(texfile)
+	tex3, err := a.Texture(texfile, &app.TextureOptions{NoFlipY: true})
 	if err != nil {
 		a.Log().Fatal("Error loading texture: %s", err)
 	}
-	tex3.SetFlipY(false)
 	// Creates sphere 3
 	geom3 := geometry.NewSphere(1, 32, 32, 0, math.Pi*2, 0, math.Pi)
 	mat3 := material.NewStandard(&math32.Color{1, 1, 1})
@@ -17,6 +17,8 @@ type FileSelectButton struct {
 	errLabel *gui.Label
 }
 
+// NewFileSelectButton creates a button which shows a file selector panel of the
+// specified size when clicked. The size is multiplied by the GUI scale factor.
 func NewFileSelectButton(path, text string, width, height float32) *FileSelectButton {
 
 	// Initialize file select button
@@ -29,7 +31,7 @@ func NewFileSelectButton(path, text string, width, height float32) *FileSelectBu
 	fsb.errLabel.SetBounded(false)
 	fsb.errLabel.SetBgColor(&math32.Color{1, 1, 1})
 	fsb.errLabel.SetColor(&math32.Color{1, 0, 0})
-	fsb.errLabel.SetPosition(4, 40)
+	fsb.errLabel.SetPosition(Scaled(4), Scaled(40))
 	fsb.errLabel.SetBorders(1, 1, 1, 1)
 	fsb.errLabel.SetPaddings(2, 6, 2, 6)
 	fsb.errLabel.SetFontSize(18)
@@ -37,7 +39,7 @@ func NewFileSelectButton(path, text string, width, height float32) *FileSelectBu
 	fsb.Button.Add(fsb.errLabel)
 
 	// Creates file select panel and add it to the button
-	fsb.FS = NewFileSelect(width, height)
+	fsb.FS = NewFileSelect(Scaled(width), Scaled(height))
 	fsb.FS.SetPosition(0, 0)
 	fsb.FS.SetVisible(false)
 	fsb.FS.SetBounded(false)
@@ -95,13 +97,14 @@ func NewFileSelect(width, height float32) *FileSelect {
 	fs := new(FileSelect)
 	fs.Panel.Initialize(width, height)
 	fs.SetBorders(1, 1, 1, 1)
-	fs.SetPaddings(4, 4, 4, 4)
+	pad := Scaled(4)
+	fs.SetPaddings(pad, pad, pad, pad)
 	fs.SetColor(math32.NewColor("white"))
 	fs.SetVisible(false)
 
 	// Set vertical box layout for the whole panel
 	l := gui.NewVBoxLayout()
-	l.SetSpacing(4)
+	l.SetSpacing(Scaled(4))
 	fs.SetLayout(l)
 
 	// Creates path label
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// WorldBoundingBox returns the bounding box in world coordinates of the
+// geometries of all graphic objects of the specified node hierarchy.
+// The returned box is empty if the hierarchy has no graphic objects.
+func WorldBoundingBox(inode core.INode) math32.Box3 {
+
+	inode.UpdateMatrixWorld()
+	var bbox math32.Box3
+	bbox.MakeEmpty()
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		node := inode.GetNode()
+		if !node.Visible() {
+			return
+		}
+		if ig, ok := inode.(graphic.IGraphic); ok {
+			geom := ig.GetGeometry()
+			if geom != nil {
+				box := geom.BoundingBox()
+				mw := node.MatrixWorld()
+				box.ApplyMatrix4(&mw)
+				bbox.Union(&box)
+			}
+		}
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(inode)
+	return bbox
+}
+
+// FrameCamera moves the camera along its current view direction so the specified
+// bounding box fits in its view, sets its target at the center of the box and,
+// for perspective cameras, sets its near and far planes to the size of the box. The orbit control uses
+// the camera target as its center of rotation, so it rotates around the box.
+func FrameCamera(icam camera.ICamera, bbox *math32.Box3) {
+
+	if bbox.IsEmpty() {
+		return
+	}
+	center := bbox.Center(nil)
+	size := bbox.Size(nil)
+	radius := size.Length() / 2
+	if radius == 0 {
+		radius = 1
+	}
+
+	// Distance to fit the bounding sphere in the vertical field of view
+	fov := float32(60)
+	persp, isPersp := icam.(*camera.Perspective)
+	if isPersp {
+		fov = persp.Fov()
+	}
+	dist := radius / math32.Sin(math32.DegToRad(fov/2))
+
+	// Keeps the current view direction
+	cam := icam.GetCamera()
+	dir := cam.Position()
+	target := cam.Target()
+	dir.Sub(&target)
+	if dir.Length() == 0 {
+		dir = math32.Vector3{0, 0, 1}
+	}
+	dir.Normalize()
+	pos := dir
+	pos.MultiplyScalar(dist).Add(center)
+	cam.SetPositionVec(&pos)
+	cam.LookAt(center)
+
+	// Clipping planes
+	if isPersp {
+		persp.SetNear(dist / 1000)
+		persp.SetFar((dist + radius) * 4)
+	}
+}
+
+// FrameNode frames the camera on the specified node hierarchy
+func FrameNode(icam camera.ICamera, inode core.INode) {
+
+	bbox := WorldBoundingBox(inode)
+	FrameCamera(icam, &bbox)
+}
@@ -0,0 +1,197 @@
+package util
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// Clip is the interface for the animations blended by the Mixer,
+// satisfied by the engine animations and the Collada animation targets
+type Clip interface {
+	Update(delta float32) // Advances the animation and sets the properties of its target nodes
+}
+
+// Mixer blends the position, rotation and scale of the nodes of a hierarchy
+// animated by several clips with weights, allowing smooth transitions between
+// clips. The engine animations overwrite the properties of their target nodes,
+// so at each update the mixer resets the nodes to their rest pose, applies each
+// clip in turn, saves the resulting poses and sets the nodes to their weighted average.
+// Other animated properties, such as morph target weights, are not blended and
+// keep the value set by the last clip.
+type Mixer struct {
+	nodes []*core.Node // Nodes of the animated hierarchy
+	rest  []nodePose   // Rest pose of the nodes
+	poses []nodePose   // Pose accumulated for the current update
+	clips []*MixerClip // Clips being blended
+}
+
+// MixerClip is a clip added to a Mixer with its blending weight
+type MixerClip struct {
+	clip   Clip
+	weight float32 // Current weight
+	target float32 // Weight at the end of the current fade
+	rate   float32 // Weight change per second of the current fade
+}
+
+// nodePose is the transform of a node
+type nodePose struct {
+	pos   math32.Vector3
+	quat  math32.Quaternion
+	scale math32.Vector3
+}
+
+// NewMixer creates and returns a mixer for the clips which animate the specified
+// node hierarchy. The current transforms of the nodes are used as the rest pose.
+func NewMixer(root core.INode) *Mixer {
+
+	m := new(Mixer)
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		node := inode.GetNode()
+		m.nodes = append(m.nodes, node)
+		m.rest = append(m.rest, getPose(node))
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+	walk(root)
+	m.poses = make([]nodePose, len(m.nodes))
+	return m
+}
+
+// Add adds a clip to the mixer with the specified initial weight and returns its mixer clip
+func (m *Mixer) Add(clip Clip, weight float32) *MixerClip {
+
+	mc := &MixerClip{clip: clip, weight: weight, target: weight}
+	m.clips = append(m.clips, mc)
+	return mc
+}
+
+// Clips returns the clips of the mixer in the order they were added
+func (m *Mixer) Clips() []*MixerClip {
+
+	return m.clips
+}
+
+// CrossFade fades out all the clips and fades in the specified clip
+// to full weight in the specified duration in seconds
+func (m *Mixer) CrossFade(to *MixerClip, duration float32) {
+
+	for _, mc := range m.clips {
+		if mc == to {
+			mc.FadeTo(1, duration)
+		} else {
+			mc.FadeTo(0, duration)
+		}
+	}
+}
+
+// Update advances all the clips by the specified time in seconds,
+// updates the fades and sets the nodes to the blended pose.
+// Clips with zero weight are also advanced so they stay synchronized.
+func (m *Mixer) Update(delta float32) {
+
+	for i := range m.poses {
+		m.poses[i] = nodePose{}
+	}
+	total := float32(0)
+	for _, mc := range m.clips {
+		mc.updateFade(delta)
+
+		// Applies the clip over the rest pose
+		for i, node := range m.nodes {
+			setPose(node, &m.rest[i])
+		}
+		mc.clip.Update(delta)
+		if mc.weight <= 0 {
+			continue
+		}
+		total += mc.weight
+
+		// Accumulates the weighted pose of the clip
+		for i, node := range m.nodes {
+			p := getPose(node)
+			acc := &m.poses[i]
+			p.pos.MultiplyScalar(mc.weight)
+			acc.pos.Add(&p.pos)
+			p.scale.MultiplyScalar(mc.weight)
+			acc.scale.Add(&p.scale)
+			// Quaternions in opposite hemispheres are negated before adding
+			if acc.quat.Dot(&p.quat) < 0 {
+				p.quat.Set(-p.quat.X, -p.quat.Y, -p.quat.Z, -p.quat.W)
+			}
+			acc.quat.Set(
+				acc.quat.X+p.quat.X*mc.weight,
+				acc.quat.Y+p.quat.Y*mc.weight,
+				acc.quat.Z+p.quat.Z*mc.weight,
+				acc.quat.W+p.quat.W*mc.weight,
+			)
+		}
+	}
+
+	// Sets the blended pose or the rest pose if no clip has weight
+	for i, node := range m.nodes {
+		if total <= 0 {
+			setPose(node, &m.rest[i])
+			continue
+		}
+		p := &m.poses[i]
+		p.pos.MultiplyScalar(1 / total)
+		p.scale.MultiplyScalar(1 / total)
+		p.quat.Normalize()
+		setPose(node, p)
+	}
+}
+
+// Weight returns the current weight of the clip
+func (mc *MixerClip) Weight() float32 {
+
+	return mc.weight
+}
+
+// SetWeight sets the weight of the clip, cancelling its fade
+func (mc *MixerClip) SetWeight(weight float32) {
+
+	mc.weight = weight
+	mc.target = weight
+	mc.rate = 0
+}
+
+// FadeTo changes the weight of the clip to the specified weight
+// linearly in the specified duration in seconds
+func (mc *MixerClip) FadeTo(weight, duration float32) {
+
+	if duration <= 0 {
+		mc.SetWeight(weight)
+		return
+	}
+	mc.target = weight
+	mc.rate = (weight - mc.weight) / duration
+}
+
+// updateFade updates the weight of the clip during a fade
+func (mc *MixerClip) updateFade(delta float32) {
+
+	if mc.rate == 0 {
+		return
+	}
+	mc.weight += mc.rate * delta
+	if (mc.rate > 0 && mc.weight >= mc.target) || (mc.rate < 0 && mc.weight <= mc.target) {
+		mc.weight = mc.target
+		mc.rate = 0
+	}
+}
+
+// getPose returns the transform of the specified node
+func getPose(node *core.Node) nodePose {
+
+	return nodePose{pos: node.Position(), quat: node.Quaternion(), scale: node.Scale()}
+}
+
+// setPose sets the transform of the specified node
+func setPose(node *core.Node, p *nodePose) {
+
+	node.SetPositionVec(&p.pos)
+	node.SetQuaternionQuat(&p.quat)
+	node.SetScaleVec(&p.scale)
+}
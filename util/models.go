@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/g3n/engine/core"
+)
+
+// ModelAnimation is the interface for the animations of loaded models
+type ModelAnimation interface {
+	Update(delta float32) // Updates the animation by the specified time in seconds
+	SetLoop(loop bool)    // Sets if the animation restarts at its end
+	Reset()               // Restarts the animation
+}
+
+// ModelLoader is the interface for model file loaders
+type ModelLoader interface {
+	Extensions() []string                                    // File extensions handled by the loader, in lower case including the dot
+	Load(fpath string) (core.INode, []ModelAnimation, error) // Loads the model and its animations from the specified file
+}
+
+// Directories searched for the texture images of the loaded models
+var (
+	texturePathsMu sync.Mutex
+	texturePaths   []string
+)
+
+// SetTexturePaths sets the directories searched, in order, for the texture images
+// of the loaded models after the model directory and its textures subdirectory.
+// It is safe to call while models are loaded in other goroutines.
+func SetTexturePaths(dirs ...string) {
+
+	texturePathsMu.Lock()
+	defer texturePathsMu.Unlock()
+	texturePaths = append([]string(nil), dirs...)
+}
+
+// TexturePaths returns a copy of the directories set by SetTexturePaths
+func TexturePaths() []string {
+
+	texturePathsMu.Lock()
+	defer texturePathsMu.Unlock()
+	return append([]string(nil), texturePaths...)
+}
+
+// modelLoaders maps file extensions to the functions which create their loaders
+var modelLoaders = make(map[string]func() ModelLoader)
+
+// RegisterModelLoader registers the specified function, which creates a loader,
+// for the file extensions of its loaders. A new loader is created for each loaded file,
+// so loaders can keep the state and diagnostics of their file and be used concurrently.
+// All the model files, from scene files and from the loader demos, are loaded
+// with the registered loaders. Must be called from an init function.
+// A loader registered later for the same extension replaces the previous one.
+func RegisterModelLoader(newLoader func() ModelLoader) {
+
+	for _, ext := range newLoader().Extensions() {
+		modelLoaders[strings.ToLower(ext)] = newLoader
+	}
+}
+
+// FindModelLoader returns a new loader registered for the extension of the specified file
+func FindModelLoader(fpath string) (ModelLoader, error) {
+
+	ext := strings.ToLower(filepath.Ext(fpath))
+	newLoader := modelLoaders[ext]
+	if newLoader == nil {
+		return nil, fmt.Errorf("unrecognized file extension:%s", ext)
+	}
+	return newLoader(), nil
+}
+
+// ModelExtensions returns the sorted list of all registered model file extensions
+func ModelExtensions() []string {
+
+	exts := []string{}
+	for ext := range modelLoaders {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// ModelWriter is a function which writes the specified node hierarchy to a model file
+// and returns the problems of the conversion which did not prevent writing the file
+type ModelWriter func(root core.INode, fpath string) ([]string, error)
+
+// modelWriters maps file extensions to their model writers
+var modelWriters = make(map[string]ModelWriter)
+
+// RegisterModelWriter registers the specified model writer for the specified
+// file extensions, including the dot. Must be called from an init function.
+func RegisterModelWriter(w ModelWriter, exts ...string) {
+
+	for _, ext := range exts {
+		modelWriters[strings.ToLower(ext)] = w
+	}
+}
+
+// FindModelWriter returns the model writer registered for the extension
+// of the specified file or nil if there is none
+func FindModelWriter(fpath string) ModelWriter {
+
+	return modelWriters[strings.ToLower(filepath.Ext(fpath))]
+}
+
+// LoadModel loads the specified model file with the loader registered
+// for its extension and returns its root node
+func LoadModel(fpath string) (core.INode, error) {
+
+	l, err := FindModelLoader(fpath)
+	if err != nil {
+		return nil, err
+	}
+	node, _, err := l.Load(fpath)
+	return node, err
+}
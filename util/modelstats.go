@@ -0,0 +1,163 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// ModelStats contains statistics and diagnostics of a node hierarchy
+type ModelStats struct {
+	Meshes        int            // Number of meshes
+	Vertices      int            // Number of vertices of all meshes
+	Triangles     int            // Number of triangles of all meshes
+	Materials     int            // Number of distinct materials
+	MaterialTypes map[string]int // Number of distinct materials by type
+	Textures      []TextureStats // Distinct textures used by the materials
+	BBox          math32.Box3    // World bounding box
+	Warnings      []string       // Problems found in the meshes
+}
+
+// TextureStats contains the resolution and estimated GPU memory of a texture
+type TextureStats struct {
+	Width  int // Width in pixels or zero if unknown
+	Height int // Height in pixels or zero if unknown
+	Memory int // Estimated GPU memory in bytes for RGBA pixels with mipmaps
+}
+
+// NewModelStats computes and returns the statistics of the specified node hierarchy
+func NewModelStats(inode core.INode) *ModelStats {
+
+	ms := &ModelStats{MaterialTypes: make(map[string]int)}
+	ms.BBox = WorldBoundingBox(inode)
+	mats := make(map[*material.Material]bool)
+	texs := make(map[*texture.Texture2D]bool)
+	var walk func(inode core.INode)
+	walk = func(inode core.INode) {
+		switch g := inode.(type) {
+		case *graphic.Mesh:
+			ms.addMesh(g, mats, texs)
+		case *graphic.RiggedMesh:
+			ms.addMesh(g.Mesh, mats, texs)
+		}
+		for _, child := range inode.GetNode().Children() {
+			walk(child)
+		}
+	}
+	walk(inode)
+	return ms
+}
+
+// addMesh adds the statistics of the specified mesh and its materials and textures
+// which were not found before
+func (ms *ModelStats) addMesh(mesh *graphic.Mesh, mats map[*material.Material]bool, texs map[*texture.Texture2D]bool) {
+
+	ms.Meshes++
+	name := mesh.Name()
+	if name == "" {
+		name = fmt.Sprintf("#%d", ms.Meshes)
+	}
+
+	// Counts vertices and triangles
+	geom := mesh.GetGeometry()
+	vertices := 0
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		vertices++
+		return false
+	})
+	ms.Vertices += vertices
+	if indices := geom.Indices(); len(indices) > 0 {
+		ms.Triangles += len(indices) / 3
+	} else {
+		ms.Triangles += vertices / 3
+	}
+
+	// Checks vertex attributes
+	if geom.VBO(gls.VertexNormal) == nil {
+		ms.Warnings = append(ms.Warnings, fmt.Sprintf("mesh %s: missing normals", name))
+	}
+	hasUV := geom.VBO(gls.VertexTexcoord) != nil
+
+	// Materials and textures
+	for _, gm := range mesh.Materials() {
+		imat := gm.GetMaterial()
+		mat := imat.GetMaterial()
+		if mats[mat] {
+			continue
+		}
+		mats[mat] = true
+		ms.Materials++
+		ms.MaterialTypes[fmt.Sprintf("%T", imat)]++
+		textures := mat.Textures()
+		if len(textures) > 0 && !hasUV {
+			ms.Warnings = append(ms.Warnings, fmt.Sprintf("mesh %s: textured material without UVs", name))
+		}
+		for _, tex := range textures {
+			if texs[tex] {
+				continue
+			}
+			texs[tex] = true
+			ts := TextureStats{Width: tex.Width(), Height: tex.Height()}
+			// Mipmaps use one third more memory
+			ts.Memory = ts.Width * ts.Height * 4 * 4 / 3
+			ms.Textures = append(ms.Textures, ts)
+		}
+	}
+}
+
+// TextureMemory returns the estimated GPU memory in bytes of all the textures
+func (ms *ModelStats) TextureMemory() int {
+
+	total := 0
+	for _, ts := range ms.Textures {
+		total += ts.Memory
+	}
+	return total
+}
+
+// Lines returns the statistics formatted as text lines
+func (ms *ModelStats) Lines() []string {
+
+	lines := []string{
+		fmt.Sprintf("Meshes: %d", ms.Meshes),
+		fmt.Sprintf("Vertices: %d", ms.Vertices),
+		fmt.Sprintf("Triangles: %d", ms.Triangles),
+	}
+	if !ms.BBox.IsEmpty() {
+		size := ms.BBox.Size(nil)
+		lines = append(lines, fmt.Sprintf("Size: %.3g x %.3g x %.3g", size.X, size.Y, size.Z))
+	}
+
+	types := []string{}
+	for t := range ms.MaterialTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	lines = append(lines, fmt.Sprintf("Materials: %d", ms.Materials))
+	for _, t := range types {
+		lines = append(lines, fmt.Sprintf("  %s: %d", t, ms.MaterialTypes[t]))
+	}
+
+	lines = append(lines, fmt.Sprintf("Textures: %d (%.1f MB)", len(ms.Textures), float64(ms.TextureMemory())/(1024*1024)))
+	for i, ts := range ms.Textures {
+		if ts.Width == 0 {
+			lines = append(lines, fmt.Sprintf("  #%d: unknown size", i))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  #%d: %dx%d (%.1f MB)", i, ts.Width, ts.Height, float64(ts.Memory)/(1024*1024)))
+	}
+
+	if len(ms.Warnings) > 0 {
+		lines = append(lines, fmt.Sprintf("Warnings: %d", len(ms.Warnings)))
+		for _, w := range ms.Warnings {
+			lines = append(lines, "  "+w)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,23 @@
+package util
+
+// uiScale is the GUI scale factor used by the helpers in this package
+var uiScale float32 = 1
+
+// SetUIScale sets the GUI scale factor used by the helpers in this package.
+// It is set by the application from the -uiscale flag or the monitor resolution.
+func SetUIScale(scale float32) {
+
+	uiScale = scale
+}
+
+// UIScale returns the GUI scale factor
+func UIScale() float32 {
+
+	return uiScale
+}
+
+// Scaled returns the specified GUI size in pixels multiplied by the GUI scale factor
+func Scaled(size float32) float32 {
+
+	return size * uiScale
+}
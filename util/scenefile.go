@@ -0,0 +1,514 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"gopkg.in/yaml.v2"
+)
+
+// SceneFile describes a 3D scene which can be written in YAML or JSON
+// and built without any Go code.
+type SceneFile struct {
+	Camera     *SceneCamera `yaml:"camera,omitempty" json:"camera,omitempty"`
+	Background SceneColor   `yaml:"background,omitempty,flow" json:"background,omitempty"`
+	Ambient    *float32     `yaml:"ambient,omitempty" json:"ambient,omitempty"`
+	Nodes      []*SceneNode `yaml:"nodes,omitempty" json:"nodes,omitempty"`
+	dir        string       // directory used to resolve relative paths
+}
+
+// SceneCamera describes the initial camera pose
+type SceneCamera struct {
+	Position []float32 `yaml:"position,omitempty,flow" json:"position,omitempty"`
+	Target   []float32 `yaml:"target,omitempty,flow" json:"target,omitempty"`
+	Fov      float32   `yaml:"fov,omitempty" json:"fov,omitempty"`
+	Zoom     float32   `yaml:"zoom,omitempty" json:"zoom,omitempty"`
+}
+
+// SceneNode describes a node of the scene hierarchy.
+// A node contains at most one of: geometry (with optional material), light or model.
+// A node with none of them is an empty group.
+type SceneNode struct {
+	Name      string          `yaml:"name,omitempty" json:"name,omitempty"`
+	Position  []float32       `yaml:"position,omitempty,flow" json:"position,omitempty"`
+	Rotation  []float32       `yaml:"rotation,omitempty,flow" json:"rotation,omitempty"` // Euler angles in degrees
+	Scale     []float32       `yaml:"scale,omitempty,flow" json:"scale,omitempty"`
+	Visible   *bool           `yaml:"visible,omitempty" json:"visible,omitempty"`
+	Geometry  *SceneGeometry  `yaml:"geometry,omitempty" json:"geometry,omitempty"`
+	Material  *SceneMaterial  `yaml:"material,omitempty" json:"material,omitempty"`
+	Light     *SceneLight     `yaml:"light,omitempty" json:"light,omitempty"`
+	Model     string          `yaml:"model,omitempty" json:"model,omitempty"`
+	Animation *SceneAnimation `yaml:"animation,omitempty" json:"animation,omitempty"`
+	Children  []*SceneNode    `yaml:"children,omitempty" json:"children,omitempty"`
+}
+
+// SceneGeometry describes one of the engine built-in geometries.
+// Type is one of: box, cube, sphere, cylinder, cone, plane, circle, torus
+// or mesh, which is built from the specified vertex data.
+type SceneGeometry struct {
+	Type         string    `yaml:"type" json:"type"`
+	Width        float32   `yaml:"width,omitempty" json:"width,omitempty"`
+	Height       float32   `yaml:"height,omitempty" json:"height,omitempty"`
+	Length       float32   `yaml:"length,omitempty" json:"length,omitempty"`
+	Size         float32   `yaml:"size,omitempty" json:"size,omitempty"`
+	Radius       float32   `yaml:"radius,omitempty" json:"radius,omitempty"`
+	RadiusTop    float32   `yaml:"radiusTop,omitempty" json:"radiusTop,omitempty"`
+	RadiusBottom float32   `yaml:"radiusBottom,omitempty" json:"radiusBottom,omitempty"`
+	Tube         float32   `yaml:"tube,omitempty" json:"tube,omitempty"`
+	Segments     int       `yaml:"segments,omitempty" json:"segments,omitempty"`
+	Positions    []float32 `yaml:"positions,omitempty,flow" json:"positions,omitempty"`
+	Normals      []float32 `yaml:"normals,omitempty,flow" json:"normals,omitempty"`
+	Indices      []uint32  `yaml:"indices,omitempty,flow" json:"indices,omitempty"`
+}
+
+// SceneMaterial describes a material.
+// Type is one of: standard (default), phong, basic.
+type SceneMaterial struct {
+	Type      string     `yaml:"type,omitempty" json:"type,omitempty"`
+	Color     SceneColor `yaml:"color,omitempty,flow" json:"color,omitempty"`
+	Emissive  SceneColor `yaml:"emissive,omitempty,flow" json:"emissive,omitempty"`
+	Specular  SceneColor `yaml:"specular,omitempty,flow" json:"specular,omitempty"`
+	Shininess float32    `yaml:"shininess,omitempty" json:"shininess,omitempty"`
+	Opacity   *float32   `yaml:"opacity,omitempty" json:"opacity,omitempty"`
+	Wireframe bool       `yaml:"wireframe,omitempty" json:"wireframe,omitempty"`
+	Side      string     `yaml:"side,omitempty" json:"side,omitempty"` // front, back or double
+	Texture   string     `yaml:"texture,omitempty" json:"texture,omitempty"`
+	Repeat    []float32  `yaml:"repeat,omitempty,flow" json:"repeat,omitempty"`
+}
+
+// SceneLight describes a light.
+// Type is one of: ambient, directional, point, spot.
+type SceneLight struct {
+	Type      string     `yaml:"type" json:"type"`
+	Color     SceneColor `yaml:"color,omitempty,flow" json:"color,omitempty"`
+	Intensity *float32   `yaml:"intensity,omitempty" json:"intensity,omitempty"`
+	Direction []float32  `yaml:"direction,omitempty,flow" json:"direction,omitempty"`
+	Cutoff    float32    `yaml:"cutoff,omitempty" json:"cutoff,omitempty"`
+	Linear    *float32   `yaml:"linear,omitempty" json:"linear,omitempty"`
+	Quadratic *float32   `yaml:"quadratic,omitempty" json:"quadratic,omitempty"`
+}
+
+// SceneAnimation describes a simple procedural node animation
+type SceneAnimation struct {
+	Spin      []float32 `yaml:"spin,omitempty,flow" json:"spin,omitempty"`           // Rotation speed in degrees/second for each axis
+	Oscillate []float32 `yaml:"oscillate,omitempty,flow" json:"oscillate,omitempty"` // Maximum displacement from the initial position
+	Period    float32   `yaml:"period,omitempty" json:"period,omitempty"`            // Oscillation period in seconds
+}
+
+// SceneColor is a RGB color which can be specified
+// as a list of components or as a color name
+type SceneColor []float32
+
+// UnmarshalYAML decodes a color from a name such as "white" or from a [r, g, b] list
+func (c *SceneColor) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	var name string
+	if err := unmarshal(&name); err == nil {
+		color, ok := math32.IsColorName(name)
+		if !ok {
+			return fmt.Errorf("invalid color name:%s", name)
+		}
+		*c = SceneColor{color.R, color.G, color.B}
+		return nil
+	}
+	var comps []float32
+	if err := unmarshal(&comps); err != nil {
+		return err
+	}
+	if len(comps) != 3 {
+		return fmt.Errorf("color must have 3 components")
+	}
+	*c = comps
+	return nil
+}
+
+// color returns the engine color or the specified default if not set
+func (c SceneColor) color(def *math32.Color) *math32.Color {
+
+	if len(c) != 3 {
+		return def
+	}
+	return &math32.Color{c[0], c[1], c[2]}
+}
+
+// SceneInstance is the result of building a scene file
+type SceneInstance struct {
+	Root  *core.Node       // Root node containing all the scene nodes
+	anims []*sceneNodeAnim // Active node animations
+}
+
+// sceneNodeAnim keeps the state of one node animation
+type sceneNodeAnim struct {
+	node core.INode
+	desc *SceneAnimation
+	base math32.Vector3
+	time float32
+}
+
+// DecodeSceneFile reads and decodes the specified scene file.
+// As JSON is a subset of YAML both formats are accepted.
+func DecodeSceneFile(fpath string) (*SceneFile, error) {
+
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	sf := new(SceneFile)
+	err = yaml.Unmarshal(data, sf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(fpath), err)
+	}
+	sf.dir = filepath.Dir(fpath)
+	return sf, nil
+}
+
+// ApplyCamera sets the specified camera pose from the scene camera description
+func (sf *SceneFile) ApplyCamera(icam camera.ICamera) {
+
+	if sf.Camera == nil {
+		return
+	}
+	cam := icam.GetCamera()
+	if len(sf.Camera.Position) == 3 {
+		cam.SetPositionVec(vec3(sf.Camera.Position, nil))
+	}
+	cam.LookAt(vec3(sf.Camera.Target, &math32.Vector3{0, 0, 0}))
+	switch c := icam.(type) {
+	case *camera.Perspective:
+		if sf.Camera.Fov > 0 {
+			c.SetFov(sf.Camera.Fov)
+		}
+	case *camera.Orthographic:
+		if sf.Camera.Zoom > 0 {
+			c.SetZoom(sf.Camera.Zoom)
+		}
+	}
+}
+
+// Build creates the engine objects for all the nodes of the scene file
+func (sf *SceneFile) Build() (*SceneInstance, error) {
+
+	si := new(SceneInstance)
+	si.Root = core.NewNode()
+	for _, nd := range sf.Nodes {
+		n, err := sf.buildNode(si, nd)
+		if err != nil {
+			si.Root.Dispose()
+			return nil, err
+		}
+		si.Root.Add(n)
+	}
+	return si, nil
+}
+
+// Update advances the scene animations by the specified time in seconds
+func (si *SceneInstance) Update(dt float32) {
+
+	for _, sa := range si.anims {
+		sa.time += dt
+		node := sa.node.GetNode()
+		if len(sa.desc.Spin) == 3 {
+			node.RotateX(math32.DegToRad(sa.desc.Spin[0]) * dt)
+			node.RotateY(math32.DegToRad(sa.desc.Spin[1]) * dt)
+			node.RotateZ(math32.DegToRad(sa.desc.Spin[2]) * dt)
+		}
+		if len(sa.desc.Oscillate) == 3 {
+			period := sa.desc.Period
+			if period <= 0 {
+				period = 1
+			}
+			f := math32.Sin(2 * math32.Pi * sa.time / period)
+			node.SetPosition(
+				sa.base.X+sa.desc.Oscillate[0]*f,
+				sa.base.Y+sa.desc.Oscillate[1]*f,
+				sa.base.Z+sa.desc.Oscillate[2]*f,
+			)
+		}
+	}
+}
+
+// path returns the absolute path of a file referenced by the scene file
+func (sf *SceneFile) path(fpath string) string {
+
+	if filepath.IsAbs(fpath) {
+		return fpath
+	}
+	return filepath.Join(sf.dir, fpath)
+}
+
+// savedNode returns a copy of the specified node description, kept by its built node
+// for saving the scene, with the paths of its model and texture files made absolute
+// as the scene may be saved to another directory
+func (sf *SceneFile) savedNode(nd *SceneNode) *SceneNode {
+
+	saved := *nd
+	if saved.Model != "" {
+		saved.Model = absPath(sf.path(saved.Model))
+	}
+	if nd.Material != nil && nd.Material.Texture != "" {
+		md := *nd.Material
+		md.Texture = absPath(sf.path(md.Texture))
+		saved.Material = &md
+	}
+	return &saved
+}
+
+// absPath returns the absolute form of the specified path or the path itself on error
+func absPath(fpath string) string {
+
+	abs, err := filepath.Abs(fpath)
+	if err != nil {
+		return fpath
+	}
+	return abs
+}
+
+// buildNode creates the engine node for the specified node description and its children
+func (sf *SceneFile) buildNode(si *SceneInstance, nd *SceneNode) (core.INode, error) {
+
+	var inode core.INode
+	var err error
+	switch {
+	case nd.Geometry != nil:
+		inode, err = sf.buildMesh(nd)
+	case nd.Light != nil:
+		inode, err = buildLight(nd.Light)
+	case nd.Model != "":
+		inode, err = LoadModel(sf.path(nd.Model))
+	default:
+		inode = core.NewNode()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("node %q: %v", nd.Name, err)
+	}
+
+	// Sets node transform and properties
+	node := inode.GetNode()
+	if nd.Name != "" {
+		node.SetName(nd.Name)
+	}
+	if len(nd.Position) == 3 {
+		node.SetPositionVec(vec3(nd.Position, nil))
+	}
+	if len(nd.Rotation) == 3 {
+		node.SetRotation(
+			math32.DegToRad(nd.Rotation[0]),
+			math32.DegToRad(nd.Rotation[1]),
+			math32.DegToRad(nd.Rotation[2]),
+		)
+	}
+	if len(nd.Scale) == 3 {
+		node.SetScaleVec(vec3(nd.Scale, nil))
+	}
+	if nd.Visible != nil {
+		node.SetVisible(*nd.Visible)
+	}
+	// Keeps the description for saving the scene
+	node.SetUserData(sf.savedNode(nd))
+	if nd.Animation != nil {
+		si.anims = append(si.anims, &sceneNodeAnim{node: inode, desc: nd.Animation, base: node.Position()})
+	}
+
+	// Builds children
+	for _, cd := range nd.Children {
+		child, err := sf.buildNode(si, cd)
+		if err != nil {
+			inode.Dispose()
+			return nil, err
+		}
+		node.Add(child)
+	}
+	return inode, nil
+}
+
+// buildMesh creates a mesh from a node geometry and material description
+func (sf *SceneFile) buildMesh(nd *SceneNode) (*graphic.Mesh, error) {
+
+	geom, err := buildGeometry(nd.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	md := nd.Material
+	if md == nil {
+		md = &SceneMaterial{}
+	}
+	mat, err := sf.buildMaterial(md)
+	if err != nil {
+		return nil, err
+	}
+	return graphic.NewMesh(geom, mat), nil
+}
+
+// buildGeometry creates one of the engine built-in geometries
+func buildGeometry(gd *SceneGeometry) (geometry.IGeometry, error) {
+
+	// Returns the specified value or a default if not set
+	def := func(v, d float32) float32 {
+		if v == 0 {
+			return d
+		}
+		return v
+	}
+	segs := gd.Segments
+	if segs <= 0 {
+		segs = 32
+	}
+	switch strings.ToLower(gd.Type) {
+	case "box":
+		return geometry.NewBox(def(gd.Width, 1), def(gd.Height, 1), def(gd.Length, 1)), nil
+	case "cube":
+		return geometry.NewCube(def(gd.Size, 1)), nil
+	case "sphere":
+		return geometry.NewSphere(float64(def(gd.Radius, 0.5)), segs, segs, 0, math32.Pi*2, 0, math32.Pi), nil
+	case "cylinder":
+		top := def(gd.RadiusTop, def(gd.Radius, 0.5))
+		bottom := def(gd.RadiusBottom, def(gd.Radius, 0.5))
+		return geometry.NewCylinder(float64(top), float64(bottom), float64(def(gd.Height, 1)), segs, 1, 0, 2*math32.Pi, true, true), nil
+	case "cone":
+		return geometry.NewCylinder(0, float64(def(gd.Radius, 0.5)), float64(def(gd.Height, 1)), segs, 1, 0, 2*math32.Pi, true, true), nil
+	case "plane":
+		return geometry.NewPlane(def(gd.Width, 1), def(gd.Height, 1), 1, 1), nil
+	case "circle":
+		return geometry.NewCircle(float64(def(gd.Radius, 0.5)), segs), nil
+	case "torus":
+		return geometry.NewTorus(float64(def(gd.Radius, 0.5)), float64(def(gd.Tube, 0.2)), 16, segs, 2*math32.Pi), nil
+	case "mesh":
+		if len(gd.Positions) == 0 || len(gd.Positions)%3 != 0 {
+			return nil, fmt.Errorf("mesh positions must be a non empty list of x, y, z coordinates")
+		}
+		geom := geometry.NewGeometry()
+		geom.AddVBO(gls.NewVBO(math32.ArrayF32(gd.Positions)).AddAttrib(gls.VertexPosition))
+		if len(gd.Normals) == len(gd.Positions) {
+			geom.AddVBO(gls.NewVBO(math32.ArrayF32(gd.Normals)).AddAttrib(gls.VertexNormal))
+		}
+		if len(gd.Indices) > 0 {
+			geom.SetIndices(math32.ArrayU32(gd.Indices))
+		}
+		return geom, nil
+	}
+	return nil, fmt.Errorf("invalid geometry type:%q", gd.Type)
+}
+
+// buildMaterial creates a material from its description
+func (sf *SceneFile) buildMaterial(md *SceneMaterial) (material.IMaterial, error) {
+
+	var mat material.IMaterial
+	var base *material.Material
+	color := md.Color.color(&math32.Color{1, 1, 1})
+	switch strings.ToLower(md.Type) {
+	case "", "standard":
+		m := material.NewStandard(color)
+		m.SetEmissiveColor(md.Emissive.color(&math32.Color{0, 0, 0}))
+		m.SetSpecularColor(md.Specular.color(&math32.Color{0.5, 0.5, 0.5}))
+		if md.Shininess > 0 {
+			m.SetShininess(md.Shininess)
+		}
+		if md.Opacity != nil {
+			m.SetOpacity(*md.Opacity)
+		}
+		mat, base = m, m.GetMaterial()
+	case "phong":
+		m := material.NewPhong(color)
+		m.SetEmissiveColor(md.Emissive.color(&math32.Color{0, 0, 0}))
+		m.SetSpecularColor(md.Specular.color(&math32.Color{0.5, 0.5, 0.5}))
+		if md.Shininess > 0 {
+			m.SetShininess(md.Shininess)
+		}
+		if md.Opacity != nil {
+			m.SetOpacity(*md.Opacity)
+		}
+		mat, base = m, m.GetMaterial()
+	case "basic":
+		m := material.NewBasic()
+		mat, base = m, m.GetMaterial()
+	default:
+		return nil, fmt.Errorf("invalid material type:%q", md.Type)
+	}
+
+	base.SetWireframe(md.Wireframe)
+	switch strings.ToLower(md.Side) {
+	case "", "front":
+		base.SetSide(material.SideFront)
+	case "back":
+		base.SetSide(material.SideBack)
+	case "double":
+		base.SetSide(material.SideDouble)
+	default:
+		return nil, fmt.Errorf("invalid material side:%q", md.Side)
+	}
+
+	// Loads optional texture
+	if md.Texture != "" {
+		tex, err := texture.NewTexture2DFromImage(sf.path(md.Texture))
+		if err != nil {
+			return nil, err
+		}
+		if len(md.Repeat) == 2 {
+			tex.SetWrapS(gls.REPEAT)
+			tex.SetWrapT(gls.REPEAT)
+			tex.SetRepeat(md.Repeat[0], md.Repeat[1])
+		}
+		base.AddTexture(tex)
+	}
+	return mat, nil
+}
+
+// buildLight creates a light from its description
+func buildLight(ld *SceneLight) (core.INode, error) {
+
+	color := ld.Color.color(&math32.Color{1, 1, 1})
+	intensity := float32(1)
+	if ld.Intensity != nil {
+		intensity = *ld.Intensity
+	}
+	switch strings.ToLower(ld.Type) {
+	case "ambient":
+		return light.NewAmbient(color, intensity), nil
+	case "directional":
+		return light.NewDirectional(color, intensity), nil
+	case "point":
+		l := light.NewPoint(color, intensity)
+		if ld.Linear != nil {
+			l.SetLinearDecay(*ld.Linear)
+		}
+		if ld.Quadratic != nil {
+			l.SetQuadraticDecay(*ld.Quadratic)
+		}
+		return l, nil
+	case "spot":
+		l := light.NewSpot(color, intensity)
+		dir := vec3(ld.Direction, &math32.Vector3{0, -1, 0})
+		l.SetDirection(dir.X, dir.Y, dir.Z)
+		if ld.Cutoff > 0 {
+			l.SetCutoffAngle(ld.Cutoff)
+		}
+		if ld.Linear != nil {
+			l.SetLinearDecay(*ld.Linear)
+		}
+		if ld.Quadratic != nil {
+			l.SetQuadraticDecay(*ld.Quadratic)
+		}
+		return l, nil
+	}
+	return nil, fmt.Errorf("invalid light type:%q", ld.Type)
+}
+
+// vec3 converts a list of 3 floats to a vector or returns the specified default
+func vec3(v []float32, def *math32.Vector3) *math32.Vector3 {
+
+	if len(v) != 3 {
+		return def
+	}
+	return &math32.Vector3{v[0], v[1], v[2]}
+}
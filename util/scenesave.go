@@ -0,0 +1,254 @@
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"gopkg.in/yaml.v2"
+)
+
+// NewSceneFileFromNode creates a scene file description of the children of the specified
+// root node and of the pose of the specified camera.
+// Cameras are not saved as nodes and the optional ambient light is saved as the scene ambient intensity.
+// Nodes built from a scene file keep their original geometry, material, light and model descriptions.
+// Other meshes are saved with their vertex data and the main properties of their first material.
+// Graphics other than meshes (helpers, lines, points, sprites) are not saved.
+func NewSceneFileFromNode(root core.INode, icam camera.ICamera, amb *light.Ambient) *SceneFile {
+
+	sf := new(SceneFile)
+	if amb != nil {
+		intensity := amb.Intensity()
+		sf.Ambient = &intensity
+	}
+	for _, child := range root.GetNode().Children() {
+		if amb != nil && child == core.INode(amb) {
+			continue
+		}
+		nd := encodeNode(child)
+		if nd != nil {
+			sf.Nodes = append(sf.Nodes, nd)
+		}
+	}
+
+	// Saves camera pose
+	if icam != nil {
+		cam := icam.GetCamera()
+		pos := cam.Position()
+		target := cam.Target()
+		sf.Camera = &SceneCamera{
+			Position: []float32{pos.X, pos.Y, pos.Z},
+			Target:   []float32{target.X, target.Y, target.Z},
+		}
+		switch c := icam.(type) {
+		case *camera.Perspective:
+			sf.Camera.Fov = c.Fov()
+		case *camera.Orthographic:
+			sf.Camera.Zoom = c.Zoom()
+		}
+	}
+	return sf
+}
+
+// SaveSceneFile saves the scene file description to the specified path.
+// The format is JSON if the file extension is ".json" and YAML otherwise.
+// The paths of the model and texture files are saved relative to the directory of the saved file.
+func (sf *SceneFile) SaveSceneFile(fpath string) error {
+
+	saved := *sf
+	saved.Nodes = sf.relativeNodes(sf.Nodes, absPath(filepath.Dir(fpath)))
+	var data []byte
+	var err error
+	if strings.ToLower(filepath.Ext(fpath)) == ".json" {
+		data, err = json.MarshalIndent(&saved, "", "  ")
+	} else {
+		data, err = yaml.Marshal(&saved)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, data, 0644)
+}
+
+// relativeNodes returns copies of the specified node descriptions and their children
+// with the paths of their model and texture files relative to the specified directory.
+// Paths which cannot be made relative, as on another Windows drive, are kept absolute.
+func (sf *SceneFile) relativeNodes(nodes []*SceneNode, dir string) []*SceneNode {
+
+	relative := func(fpath string) string {
+		abs := absPath(sf.path(fpath))
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return abs
+		}
+		return filepath.ToSlash(rel)
+	}
+	var copies []*SceneNode
+	for _, nd := range nodes {
+		cd := *nd
+		if cd.Model != "" {
+			cd.Model = relative(cd.Model)
+		}
+		if nd.Material != nil && nd.Material.Texture != "" {
+			md := *nd.Material
+			md.Texture = relative(md.Texture)
+			cd.Material = &md
+		}
+		cd.Children = sf.relativeNodes(nd.Children, dir)
+		copies = append(copies, &cd)
+	}
+	return copies
+}
+
+// encodeNode returns the description of the specified node and its children
+// or nil if the node should not be saved.
+func encodeNode(inode core.INode) *SceneNode {
+
+	// Cameras and graphics other than meshes are not saved.
+	// Skinned meshes are saved as their meshes without the skeleton.
+	if _, ok := inode.(camera.ICamera); ok {
+		return nil
+	}
+	mesh, isMesh := inode.(*graphic.Mesh)
+	if rm, ok := inode.(*graphic.RiggedMesh); ok {
+		mesh, isMesh = rm.Mesh, true
+	}
+	if _, ok := inode.(graphic.IGraphic); ok && !isMesh {
+		return nil
+	}
+
+	node := inode.GetNode()
+	nd := new(SceneNode)
+	if orig, ok := node.UserData().(*SceneNode); ok {
+		// Node built from a scene file
+		nd.Geometry = orig.Geometry
+		nd.Material = orig.Material
+		nd.Light = orig.Light
+		nd.Model = orig.Model
+		nd.Animation = orig.Animation
+	} else if isMesh {
+		nd.Geometry = encodeGeometry(mesh)
+		nd.Material = encodeMaterial(mesh.GetMaterial(0))
+	} else {
+		nd.Light = encodeLight(inode)
+	}
+
+	// Saves node properties and transform
+	nd.Name = node.Name()
+	pos := node.Position()
+	nd.Position = []float32{pos.X, pos.Y, pos.Z}
+	rot := node.Rotation()
+	nd.Rotation = []float32{math32.RadToDeg(rot.X), math32.RadToDeg(rot.Y), math32.RadToDeg(rot.Z)}
+	scale := node.Scale()
+	if scale.X != 1 || scale.Y != 1 || scale.Z != 1 {
+		nd.Scale = []float32{scale.X, scale.Y, scale.Z}
+	}
+	if !node.Visible() {
+		visible := false
+		nd.Visible = &visible
+	}
+
+	// Children of loaded models are part of the model
+	if nd.Model != "" {
+		return nd
+	}
+	for _, child := range node.Children() {
+		cd := encodeNode(child)
+		if cd != nil {
+			nd.Children = append(nd.Children, cd)
+		}
+	}
+	return nd
+}
+
+// encodeGeometry returns the description of a mesh geometry with its vertex data
+func encodeGeometry(mesh *graphic.Mesh) *SceneGeometry {
+
+	geom := mesh.GetGeometry()
+	gd := &SceneGeometry{Type: "mesh"}
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		gd.Positions = append(gd.Positions, vertex.X, vertex.Y, vertex.Z)
+		return false
+	})
+	geom.ReadVertexNormals(func(normal math32.Vector3) bool {
+		gd.Normals = append(gd.Normals, normal.X, normal.Y, normal.Z)
+		return false
+	})
+	gd.Indices = append(gd.Indices, geom.Indices()...)
+	return gd
+}
+
+// encodeMaterial returns the description of the main properties of a material
+func encodeMaterial(imat material.IMaterial) *SceneMaterial {
+
+	if imat == nil {
+		return nil
+	}
+	md := new(SceneMaterial)
+	var std *material.Standard
+	switch m := imat.(type) {
+	case *material.Standard:
+		std = m
+	case *material.Phong:
+		md.Type = "phong"
+		std = &m.Standard
+	case *material.Basic:
+		md.Type = "basic"
+	}
+
+	// Colors of the standard and phong materials
+	if std != nil {
+		c := std.AmbientColor()
+		md.Color = SceneColor{c.R, c.G, c.B}
+		c = std.EmissiveColor()
+		if c.R != 0 || c.G != 0 || c.B != 0 {
+			md.Emissive = SceneColor{c.R, c.G, c.B}
+		}
+	}
+
+	mat := imat.GetMaterial()
+	switch mat.Side() {
+	case material.SideBack:
+		md.Side = "back"
+	case material.SideDouble:
+		md.Side = "double"
+	}
+	return md
+}
+
+// encodeLight returns the description of a light or nil if the node is not a light
+func encodeLight(inode core.INode) *SceneLight {
+
+	ld := new(SceneLight)
+	var color math32.Color
+	var intensity float32
+	switch l := inode.(type) {
+	case *light.Ambient:
+		ld.Type = "ambient"
+		color, intensity = l.Color(), l.Intensity()
+	case *light.Directional:
+		ld.Type = "directional"
+		color, intensity = l.Color(), l.Intensity()
+	case *light.Point:
+		ld.Type = "point"
+		color, intensity = l.Color(), l.Intensity()
+	case *light.Spot:
+		ld.Type = "spot"
+		color, intensity = l.Color(), l.Intensity()
+		dir := l.Direction()
+		ld.Direction = []float32{dir.X, dir.Y, dir.Z}
+		ld.Cutoff = l.CutoffAngle()
+	default:
+		return nil
+	}
+	ld.Color = SceneColor{color.R, color.G, color.B}
+	ld.Intensity = &intensity
+	return ld
+}
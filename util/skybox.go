@@ -0,0 +1,64 @@
+package util
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/texture"
+)
+
+// NewSkybox creates and returns an engine skybox with the specified face textures
+// in the order: positive x, negative x, positive y, negative y, positive z and negative z,
+// which allows decoding the face images concurrently or sharing them.
+// The engine skybox only loads its faces from image files, so it is created from a blank
+// image file in a private temporary directory and its textures are then replaced.
+// The skybox takes the references to the textures, which are released on error.
+func NewSkybox(faces [6]*texture.Texture2D) (*graphic.Skybox, error) {
+
+	skybox, err := newBlankSkybox()
+	if err != nil {
+		for _, tex := range faces {
+			tex.Dispose()
+		}
+		return nil, err
+	}
+	for i, gm := range skybox.Materials() {
+		mat := gm.GetMaterial().GetMaterial()
+		for _, tex := range append([]*texture.Texture2D(nil), mat.Textures()...) {
+			mat.RemoveTexture(tex)
+			tex.Dispose()
+		}
+		mat.AddTexture(faces[i])
+	}
+	return skybox, nil
+}
+
+// newBlankSkybox creates and returns an engine skybox with a blank image in all its faces
+func newBlankSkybox() (*graphic.Skybox, error) {
+
+	dir, err := ioutil.TempDir("", "g3nd")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	f, err := os.Create(filepath.Join(dir, "blank.png"))
+	if err != nil {
+		return nil, err
+	}
+	err = png.Encode(f, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := graphic.SkyboxData{DirAndPrefix: dir + string(filepath.Separator), Extension: "png"}
+	for i := range data.Suffixes {
+		data.Suffixes[i] = "blank"
+	}
+	return graphic.NewSkybox(data)
+}